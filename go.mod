@@ -6,18 +6,21 @@ toolchain go1.24.1
 
 require (
 	github.com/Jeffail/gabs/v2 v2.7.0
+	github.com/klauspost/compress v1.18.0
+	github.com/mattn/go-isatty v0.0.19
+	github.com/mgutz/ansi v0.0.0-20200706080929-d51e80ef957d
 	github.com/michaelquigley/pfxlog v0.6.10
 	github.com/openziti/foundation/v2 v2.0.63
 	github.com/pkg/errors v0.9.1
 	github.com/sirupsen/logrus v1.9.3
 	github.com/spf13/cobra v1.9.1
+	github.com/ulikunitz/xz v0.5.15
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
-	github.com/mattn/go-isatty v0.0.19 // indirect
-	github.com/mgutz/ansi v0.0.0-20200706080929-d51e80ef957d // indirect
 	github.com/spf13/pflag v1.0.6 // indirect
 	golang.org/x/crypto v0.11.0 // indirect
 	golang.org/x/sys v0.33.0 // indirect
@@ -24,7 +24,7 @@ import (
 	"github.com/openziti/foundation/v2/stringz"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"os"
+	"io"
 	"strings"
 	"time"
 	"unicode"
@@ -35,35 +35,27 @@ const DateTimeMinutesFormat = "2006-01-02T15:04"
 const DateTimeHoursFormat = "2006-01-02T15"
 
 type ParseContext struct {
-	path              string
-	journaldTimestamp string
-	journald          bool
-	lineNumber        int
-	eof               bool
-	line              string
-	process           string
-}
-
-func (self *ParseContext) parseJournald() {
-	if self.journald && len(self.line) > 0 {
-		if self.line[0] == '-' {
-			self.process = "journald"
-			self.journaldTimestamp = ""
-		} else {
-			self.journaldTimestamp = strings.TrimSpace(self.line[:16])
-			self.line = self.line[16:] // strip timestamp
-			_, self.line = splitFirst(self.line, ' ')
-			self.process, self.line = splitFirst(self.line, ':')
-			self.process, _ = splitFirst(self.process, '[')
-		}
-	}
+	paths      []string
+	follow     *followConfig
+	options    *InputOptions
+	format     string
+	path       string
+	outerTime  time.Time
+	lastTime   time.Time
+	modTime    time.Time
+	partial    strings.Builder
+	lineNumber int
+	eof        bool
+	line       string
+	process    string
 }
 
-func (self *ParseContext) getJournaldTime() (time.Time, error) {
-	if self.journaldTimestamp == "" {
-		return time.Time{}, errors.Errorf("no journald timestamp found on line %v", self.lineNumber)
+// getOuterTime returns the timestamp added by the log collector, such as journald or docker, for the current line
+func (self *ParseContext) getOuterTime() (time.Time, error) {
+	if self.outerTime.IsZero() {
+		return time.Time{}, errors.Errorf("no log collector timestamp found on line %v", self.lineNumber)
 	}
-	return time.Parse("Jan 02 15:04:05", self.journaldTimestamp)
+	return self.outerTime, nil
 }
 
 func splitFirst(s string, c byte) (string, string) {
@@ -77,45 +69,64 @@ func splitFirst(s string, c byte) (string, string) {
 	return s[0:i], s[i+1:]
 }
 
+// ScanLines reads each of the context's paths in order, treating them as a single stream. The callback is invoked
+// once per line and a final time with eof set once all paths have been read
 func ScanLines(ctx *ParseContext, callback func(ctx *ParseContext) error) error {
-	file, err := os.Open(ctx.path)
+	for idx, path := range ctx.paths {
+		follow := ctx.follow != nil && idx == len(ctx.paths)-1
+		if err := scanFile(ctx, path, follow, callback); err != nil {
+			return err
+		}
+	}
+	ctx.eof = true
+	return callback(ctx)
+}
+
+func scanFile(ctx *ParseContext, path string, follow bool, callback func(ctx *ParseContext) error) error {
+	var file io.ReadCloser
+	var err error
+	if follow && path != StdinInput {
+		file, err = newFollowReader(path, ctx.follow)
+	} else {
+		file, err = openInput(path)
+	}
 	if err != nil {
 		return err
 	}
-	scanner := bufio.NewScanner(file)
+	defer func() { _ = file.Close() }()
 
-	// skip first line of journald output
-	if ctx.journald {
-		if scanner.Scan() {
-			line := scanner.Text()
-			if !strings.HasPrefix(line, "-- Logs begin at") {
-				if err := callback(ctx); err != nil {
-					return errors.Wrapf(err, "error parsing %v on line %v", ctx.path, ctx.lineNumber)
-				}
-			}
-			ctx.lineNumber++
-		}
-	}
+	ctx.path = path
+	ctx.lineNumber = 0
+	ctx.format = ctx.options.Format
+	ctx.partial.Reset()
+	ctx.lastTime = time.Time{}
+	ctx.modTime = getModTime(path)
+	return scanReader(ctx, file, callback)
+}
+
+// scanReader invokes the callback for each line read from the reader, once any log collector wrapping is removed
+func scanReader(ctx *ParseContext, reader io.Reader, callback func(ctx *ParseContext) error) error {
+	scanner := bufio.NewScanner(reader)
 
 	for scanner.Scan() {
-		line := scanner.Text()
-		ctx.line = line
-		ctx.parseJournald()
-		if err := callback(ctx); err != nil {
-			return errors.Wrapf(err, "error parsing %v on line %v", ctx.path, ctx.lineNumber)
+		ctx.line = scanner.Text()
+		if ctx.unwrapLine() {
+			if err := callback(ctx); err != nil {
+				return errors.Wrapf(err, "error parsing %v on line %v", ctx.path, ctx.lineNumber)
+			}
 		}
 		ctx.lineNumber++
 	}
-	ctx.eof = true
-	return callback(ctx)
+	return scanner.Err()
 }
 
 type JsonParseContext struct {
 	ParseContext
-	entry   *gabs.Container
-	cache   map[string]string
-	systemd *string
-	nonJson bytes.Buffer
+	entry     *gabs.Container
+	cache     map[string]string
+	extracted map[string]string
+	systemd   *string
+	nonJson   bytes.Buffer
 }
 
 func (self *JsonParseContext) GetString(path string) string {
@@ -130,7 +141,8 @@ func (self *JsonParseContext) GetString(path string) string {
 	}
 	v := self.entry.Search(path)
 	if v == nil || v.Data() == nil {
-		return ""
+		// fall back to the fields extracted by the filter which matched the entry
+		return self.extracted[path]
 	}
 	s, ok := v.Data().(string)
 	if !ok {
@@ -140,10 +152,27 @@ func (self *JsonParseContext) GetString(path string) string {
 	return s
 }
 
+// GetTime returns the timestamp of the current entry, falling back to the log collector timestamp for non-json lines
+func (self *JsonParseContext) GetTime() (time.Time, error) {
+	if self.entry != nil {
+		if ts := self.GetString("time"); ts != "" {
+			return time.Parse(time.RFC3339, ts)
+		}
+	}
+	return self.getOuterTime()
+}
+
 func (self *JsonParseContext) ParseJsonEntry() error {
 	self.entry = nil
+	self.cache = map[string]string{}
+	self.extracted = nil
 	input := strings.TrimLeftFunc(self.line, unicode.IsSpace)
-	if len(input) == 0 || input[0] != '{' {
+	if len(input) == 0 {
+		return nil
+	}
+
+	if input[0] != '{' {
+		self.entry = self.parseTextEntry(input)
 		return nil
 	}
 
@@ -152,58 +181,93 @@ func (self *JsonParseContext) ParseJsonEntry() error {
 		return err
 	}
 	self.entry = entry
-	self.cache = map[string]string{}
 	return nil
 }
 
 func (self *JsonParseContext) HandleNonJson() {
 	self.systemd = nil
 	if self.entry == nil {
-		if self.process != "systemd" {
+		if self.process == "systemd" {
+			line := strings.TrimSpace(self.line)
+			self.systemd = &line
+		} else {
 			self.nonJson.WriteString(self.line)
 			self.nonJson.WriteByte('\n')
 		}
-	} else {
-		self.systemd = &self.line
 	}
 }
 
-func ScanJsonLines(path string, callback func(ctx *JsonParseContext) error) error {
-	ctx := &JsonParseContext{
+// ScanJsonLines expands the given inputs, which may be files, directories or glob patterns, and scans the resulting
+// files as one continuous stream, ordered by the first timestamp found in each file
+func ScanJsonLines(inputs []string, options *InputOptions, callback func(ctx *JsonParseContext) error) error {
+	paths, err := expandInputs(inputs)
+	if err != nil {
+		return err
+	}
+	return scanJsonLines(orderByFirstTimestamp(paths, options), options, callback)
+}
+
+func scanJsonLines(paths []string, options *InputOptions, callback func(ctx *JsonParseContext) error) error {
+	return newJsonParseContext(paths, options).scan(callback)
+}
+
+func newJsonParseContext(paths []string, options *InputOptions) *JsonParseContext {
+	return &JsonParseContext{
 		ParseContext: ParseContext{
-			path:     path,
-			journald: true,
+			paths:   paths,
+			options: options,
 		},
 	}
+}
 
+func (self *JsonParseContext) scan(callback func(ctx *JsonParseContext) error) error {
 	defer func() {
 		if err := recover(); err != nil {
-			fmt.Printf("panic parsing line %v: %v with err: %v\n", ctx.lineNumber, ctx.line, err)
+			fmt.Printf("panic parsing %v line %v: %v with err: %v\n", self.path, self.lineNumber, self.line, err)
 			panic(err)
 		}
 	}()
 
-	return ScanLines(&ctx.ParseContext, func(*ParseContext) error {
-		if ctx.eof {
-			return callback(ctx)
+	return ScanLines(&self.ParseContext, self.parseLine(callback))
+}
+
+// parseLine returns a line callback which parses the current line into an entry before passing the context on
+func (self *JsonParseContext) parseLine(callback func(ctx *JsonParseContext) error) func(*ParseContext) error {
+	return func(*ParseContext) error {
+		if self.eof {
+			return callback(self)
 		}
-		if err := ctx.ParseJsonEntry(); err != nil {
+		if err := self.ParseJsonEntry(); err != nil {
 			return err
 		}
-		return callback(ctx)
-	})
+		self.updateLastTime()
+		return callback(self)
+	}
 }
 
 type LogFilter interface {
 	LogMatcher
 	Id() string
 	Desc() string
+	// MinVersion and MaxVersion give the inclusive range of ziti versions which log the entries the filter matches. An
+	// empty string leaves that end of the range open
+	MinVersion() string
+	MaxVersion() string
+	Severity() Severity
+	// Shared returns true if the id is deliberately used by filters for more than one component, such as PROCESS_START,
+	// so that lint doesn't report it
+	Shared() bool
 }
 
 type filter struct {
 	LogMatcher
-	id   string
-	desc string
+	id         string
+	desc       string
+	minVersion string
+	maxVersion string
+	severity   Severity
+	shared     bool
+	extract    []*FieldExtraction
 }
 
 func (self *filter) Id() string {
@@ -214,47 +278,171 @@ func (self *filter) Desc() string {
 	return self.desc
 }
 
+func (self *filter) MinVersion() string {
+	return self.minVersion
+}
+
+func (self *filter) MaxVersion() string {
+	return self.maxVersion
+}
+
+func (self *filter) Severity() Severity {
+	return self.severity
+}
+
+func (self *filter) Shared() bool {
+	return self.shared
+}
+
 type JsonLogsParser struct {
+	component      string
 	bucketSize     time.Duration
 	filters        []LogFilter
+	filterFiles    []string
+	filtersLoaded  bool
+	activeFilters  []LogFilter
+	versionRanges  map[LogFilter]*versionRange
+	zitiVersion    string
+	version        *zitiVersion
+	severityName   string
+	minSeverity    *Severity
+	selector       *filterSelector
+	where          string
+	whereMatcher   LogMatcher
+	allMatches     bool
+	suggestLimit   int
+	groupBy        string
+	groupLimit     int
 	maxUnmatched   int
 	ignore         []string
 	includeFilters []string
+	inputFormat    string
+	timezone       string
+	location       *time.Location
 	beforeTime     string
 	afterTime      string
 	include        LogMatcher
 	handler        EntryHandler
 	formatter      string
+	follow         bool
 }
 
 func (self *JsonLogsParser) addCommonArgs(cmd *cobra.Command) {
+	self.addFilterSetArgs(cmd)
 	cmd.Flags().StringVarP(&self.beforeTime, "before", "B", "", "Process only messages before this timestamp")
 	cmd.Flags().StringVarP(&self.afterTime, "after", "A", "", "Process only messages after this timestamp")
+	cmd.Flags().StringVar(&self.inputFormat, "input-format", InputFormatAuto, fmt.Sprintf("Specify input format: [%v]", strings.Join(InputFormats, "|")))
+	cmd.Flags().StringVar(&self.timezone, "timezone", "UTC", "Time zone of log collector timestamps which don't include one, such as journald's, e.g. Local or America/New_York")
+}
+
+func (self *JsonLogsParser) addFilterSetArgs(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&self.filterFiles, "filters-file", nil, "YAML or JSON files with additional filters or overrides of built-in filters")
+	cmd.Flags().StringVar(&self.zitiVersion, "ziti-version", "", "Only use filters for this ziti version, instead of detecting it from startup messages")
 }
 
 func (self *JsonLogsParser) addFilterArgs(cmd *cobra.Command) {
 	self.addCommonArgs(cmd)
 	cmd.Flags().IntVarP(&self.maxUnmatched, "max-unmatched", "u", 1, "Maximum unmatched log messages to output")
-	cmd.Flags().StringSliceVarP(&self.includeFilters, "include", "i", nil, "Filters to include")
+	cmd.Flags().StringSliceVarP(&self.includeFilters, "include", "i", nil, "Filters to include. Accepts ids, globs such as LINK_*, /regex/, @group and !<selector> to exclude")
+	cmd.Flags().StringSliceVar(&self.ignore, "ignore", nil, "Filters to leave out. Accepts ids, globs such as LINK_*, /regex/ and @group")
+	cmd.Flags().StringVar(&self.severityName, "min-severity", "", "Include matches of filters with at least this severity: [info|warn|error|critical]")
+	self.addWhereArgs(cmd)
+	self.addAllMatchesArgs(cmd)
+	cmd.Flags().BoolVarP(&self.follow, "follow", "F", false, "Keep reading the last log file as it grows, like tail -F")
 }
 
 func (self *JsonLogsParser) addSummarizeArgs(cmd *cobra.Command) {
 	self.addCommonArgs(cmd)
 	cmd.Flags().DurationVarP(&self.bucketSize, "interval", "n", time.Hour, "Interval for which to aggregate log messages")
 	cmd.Flags().IntVarP(&self.maxUnmatched, "max-unmatched", "u", 1, "Maximum unmatched log messages to output per bucket")
-	cmd.Flags().StringSliceVarP(&self.ignore, "ignore", "i", nil, "Filters to ignore")
+	cmd.Flags().StringSliceVarP(&self.ignore, "ignore", "i", nil, "Filters to ignore. Accepts ids, globs such as LINK_*, /regex/ and @group")
+	cmd.Flags().StringSliceVar(&self.includeFilters, "include", nil, "Only show these filters. Accepts ids, globs such as LINK_*, /regex/, @group and !<selector> to exclude")
+	cmd.Flags().StringVar(&self.severityName, "min-severity", "", "Ignore filters with a lower severity: [info|warn|error|critical]")
+	self.addWhereArgs(cmd)
+	self.addAllMatchesArgs(cmd)
+	cmd.Flags().StringVarP(&self.groupBy, "group-by", "g", "", "Break down each filter's count by the values of this field, such as routerId, serviceId, or linkId and remoteAddr, which are also taken from the message")
+	cmd.Flags().IntVar(&self.groupLimit, "top", 5, "Number of the most frequent --group-by values to show per filter per interval, or 0 for all")
+	cmd.Flags().StringVarP(&self.formatter, "output", "o", "text", "Specify output format: [text|json]")
+	cmd.Flags().BoolVarP(&self.follow, "follow", "F", false, "Keep reading the last log file as it grows, like tail -F, outputting each interval as it closes")
+}
+
+func (self *JsonLogsParser) addWhereArgs(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&self.where, "where", "w", "", `Only process entries matching this field query, ex: 'msg ~ "route" && level == error'`)
+}
+
+func (self *JsonLogsParser) addAllMatchesArgs(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&self.allMatches, "all-matches", false, "Check every filter against each entry instead of stopping at the first match, and report filters which overlap")
+}
+
+func (self *JsonLogsParser) addTimelineArgs(cmd *cobra.Command) {
+	self.addCommonArgs(cmd)
 	cmd.Flags().StringVarP(&self.formatter, "output", "o", "text", "Specify output format: [text|json]")
 }
 
 func (self *JsonLogsParser) validate() error {
+	if err := self.validateFilters(); err != nil {
+		return err
+	}
+	if err := validateInputFormat(self.inputFormat); err != nil {
+		return err
+	}
+	location, err := time.LoadLocation(self.timezone)
+	if err != nil {
+		return errors.Wrapf(err, "invalid timezone '%v'", self.timezone)
+	}
+	self.location = location
+
+	if self.severityName != "" {
+		severity, err := ParseSeverity(self.severityName)
+		if err != nil {
+			return err
+		}
+		self.minSeverity = &severity
+	}
+
+	if self.groupLimit < 0 {
+		return errors.Errorf("invalid --top %v, must be 0 or more", self.groupLimit)
+	}
+
+	if self.selector, err = newFilterSelector(self.includeFilters, self.ignore, self.filters); err != nil {
+		return err
+	}
+	if err = self.setupDateFilters(); err != nil {
+		return err
+	}
+
+	// the query is checked after the filters, rather than being part of include, so it can use extracted fields
+	if self.where != "" {
+		if self.whereMatcher, err = ParseQuery(self.where); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (self *JsonLogsParser) getInputOptions() *InputOptions {
+	return &InputOptions{
+		Format:   self.inputFormat,
+		Location: self.location,
+	}
+}
+
+func (self *JsonLogsParser) validateFilters() error {
+	if err := self.loadFilterFiles(); err != nil {
+		return err
+	}
+
 	ids := map[string]int{}
 	for idx, k := range self.filters {
 		if v, found := ids[k.Id()]; found {
 			return errors.Errorf("duplicate filter id %v at indices %v and %v", k.Id(), idx, v)
 		}
 		ids[k.Id()] = idx
+		if err := validateMatcher(k); err != nil {
+			return errors.Wrapf(err, "invalid filter %v", k.Id())
+		}
 	}
-	return self.setupDateFilters()
+	return self.setupVersions()
 }
 
 func (self *JsonLogsParser) parseDateTimeFilter(filter string) (time.Time, error) {
@@ -324,10 +512,24 @@ func (self *JsonLogsParser) setupDateFilters() error {
 	return nil
 }
 
-func (self *JsonLogsParser) ShowCategories(*cobra.Command, []string) {
+func (self *JsonLogsParser) ShowCategories(*cobra.Command, []string) error {
+	if err := self.validateFilters(); err != nil {
+		return err
+	}
 	for _, filter := range self.filters {
-		fmt.Printf("%v: %v\n", filter.Id(), filter.Desc())
+		versions := self.versionRanges[filter]
+		switch {
+		case versions.isObsolete(self.version):
+			fmt.Printf("%v: %v (%v, obsolete for %v)\n", filter.Id(), filter.Desc(), versions, self.version)
+		case !versions.contains(self.version):
+			fmt.Printf("%v: %v (%v, not yet used by %v)\n", filter.Id(), filter.Desc(), versions, self.version)
+		case versions.String() != "":
+			fmt.Printf("%v: %v (%v)\n", filter.Id(), filter.Desc(), versions)
+		default:
+			fmt.Printf("%v: %v\n", filter.Id(), filter.Desc())
+		}
 	}
+	return nil
 }
 
 type EntryHandler interface {
@@ -337,6 +539,12 @@ type EntryHandler interface {
 	HandleUnmatched(ctx *JsonParseContext) error
 }
 
+// MultiMatchHandler may be implemented by an EntryHandler which wants to be given all the filters matching an entry
+// at once, when every filter is being checked. Otherwise HandleMatch is called for each matching filter
+type MultiMatchHandler interface {
+	HandleMatches(ctx *JsonParseContext, logFilters []LogFilter) error
+}
+
 func (self *JsonLogsParser) processLogEntry(ctx *JsonParseContext) error {
 	if ctx.eof {
 		if ctx.nonJson.Len() > 0 {
@@ -384,8 +592,14 @@ func (self *JsonLogsParser) checkNonJson(ctx *JsonParseContext) error {
 	// we're past the non-json, so save current line data and clear it
 	entry := ctx.entry
 	line := ctx.line
+	cache := ctx.cache
+	extracted := ctx.extracted
+	systemd := ctx.systemd
 	ctx.line = ctx.nonJson.String()
 	ctx.entry = nil
+	ctx.cache = map[string]string{}
+	ctx.extracted = nil
+	ctx.systemd = nil
 
 	if err := self.runMatchers(ctx); err != nil {
 		return err
@@ -396,21 +610,85 @@ func (self *JsonLogsParser) checkNonJson(ctx *JsonParseContext) error {
 	// restore current line data
 	ctx.entry = entry
 	ctx.line = line
+	ctx.cache = cache
+	ctx.extracted = extracted
+	ctx.systemd = systemd
 
 	return nil
 }
 
 func (self *JsonLogsParser) runMatchers(ctx *JsonParseContext) error {
-	for _, filter := range self.filters {
+	if self.allMatches {
+		return self.runAllMatchers(ctx)
+	}
+
+	for _, filter := range self.activeFilters {
 		match, err := filter.Matches(ctx)
 		if err != nil {
 			return err
 		}
 
 		if match {
+			extractFields(ctx, filter)
+			if filter.Id() == processStartFilterId {
+				self.handleProcessStart(ctx)
+			}
+			if match, err = self.matchesWhere(ctx); err != nil || !match {
+				return err
+			}
 			return self.handler.HandleMatch(ctx, filter)
 		}
 	}
 
+	if match, err := self.matchesWhere(ctx); err != nil || !match {
+		return err
+	}
 	return self.handler.HandleUnmatched(ctx)
 }
+
+// matchesWhere checks the entry against the --where query, if one was given. It's checked once the filters have
+// matched, so the query can use the fields they extracted
+func (self *JsonLogsParser) matchesWhere(ctx *JsonParseContext) (bool, error) {
+	if self.whereMatcher == nil {
+		return true, nil
+	}
+	return self.whereMatcher.Matches(ctx)
+}
+
+// runAllMatchers checks the entry against every active filter, rather than stopping at the first one which matches
+func (self *JsonLogsParser) runAllMatchers(ctx *JsonParseContext) error {
+	var matched []LogFilter
+	for _, filter := range self.activeFilters {
+		match, err := filter.Matches(ctx)
+		if err != nil {
+			return err
+		}
+
+		if match {
+			extractFields(ctx, filter)
+			if filter.Id() == processStartFilterId {
+				self.handleProcessStart(ctx)
+			}
+			matched = append(matched, filter)
+		}
+	}
+
+	if match, err := self.matchesWhere(ctx); err != nil || !match {
+		return err
+	}
+
+	if len(matched) == 0 {
+		return self.handler.HandleUnmatched(ctx)
+	}
+
+	if multiMatchHandler, ok := self.handler.(MultiMatchHandler); ok {
+		return multiMatchHandler.HandleMatches(ctx, matched)
+	}
+
+	for _, filter := range matched {
+		if err := self.handler.HandleMatch(ctx, filter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
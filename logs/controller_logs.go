@@ -35,6 +35,7 @@ func NewCtrlLogsCommand() *cobra.Command {
 		Use:     "filter",
 		Short:   "filter controller log entries",
 		Aliases: []string{"f"},
+		Args:    cobra.MinimumNArgs(1),
 		RunE:    controllerLogs.filter,
 	}
 
@@ -44,6 +45,7 @@ func NewCtrlLogsCommand() *cobra.Command {
 		Use:     "summarize",
 		Short:   "Show controller log entry summaries",
 		Aliases: []string{"s"},
+		Args:    cobra.MinimumNArgs(1),
 		RunE:    controllerLogs.summarize,
 	}
 
@@ -55,10 +57,60 @@ func NewCtrlLogsCommand() *cobra.Command {
 		Use:     "categories",
 		Short:   "Show controller log entry categories",
 		Aliases: []string{"cat"},
-		Run:     controllerLogs.ShowCategories,
+		RunE:    controllerLogs.ShowCategories,
 	}
 
-	controllerLogsCmd.AddCommand(filterControllerLogsCmd, summarizeControllerLogsCmd, showControllerLogCategoriesCmd)
+	controllerLogs.addFilterSetArgs(showControllerLogCategoriesCmd)
+
+	linksControllerLogsCmd := &cobra.Command{
+		Use:   "links",
+		Short: "Show a timeline of when links faulted and were removed",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  controllerLogs.showLinks,
+	}
+
+	controllerLogs.addTimelineArgs(linksControllerLogsCmd)
+
+	terminatorsControllerLogsCmd := &cobra.Command{
+		Use:     "terminators",
+		Short:   "Show a timeline of when terminators were created, updated and removed",
+		Aliases: []string{"term"},
+		Args:    cobra.MinimumNArgs(1),
+		RunE:    controllerLogs.showTerminators,
+	}
+
+	controllerLogs.addTimelineArgs(terminatorsControllerLogsCmd)
+
+	restartsControllerLogsCmd := &cobra.Command{
+		Use:   "restarts",
+		Short: "Show a timeline of process restarts and the versions running after each restart",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  controllerLogs.showRestarts,
+	}
+
+	controllerLogs.addTimelineArgs(restartsControllerLogsCmd)
+
+	suggestControllerLogsCmd := &cobra.Command{
+		Use:   "suggest",
+		Short: "Cluster unmatched controller log entries into message templates and suggest filters for them",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  controllerLogs.suggestFilters,
+	}
+
+	controllerLogs.addSuggestArgs(suggestControllerLogsCmd)
+
+	coverageControllerLogsCmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "Show the percentage of controller log entries categorized by filters, per level and source file, and which filters never matched",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  controllerLogs.showCoverage,
+	}
+
+	controllerLogs.addTimelineArgs(coverageControllerLogsCmd)
+	controllerLogs.addWhereArgs(coverageControllerLogsCmd)
+
+	controllerLogsCmd.AddCommand(filterControllerLogsCmd, summarizeControllerLogsCmd, showControllerLogCategoriesCmd, linksControllerLogsCmd,
+		terminatorsControllerLogsCmd, restartsControllerLogsCmd, suggestControllerLogsCmd, coverageControllerLogsCmd)
 
 	return controllerLogsCmd
 }
@@ -68,6 +120,7 @@ type ControllerLogs struct {
 }
 
 func (self *ControllerLogs) Init() {
+	self.component = "controller"
 	self.filters = getControllerLogFilters()
 }
 
@@ -77,40 +130,45 @@ func getControllerLogFilters() []LogFilter {
 	// tls
 	result = append(result,
 		&filter{
-			id:   "TLS_UNEXPECTED",
-			desc: "received unexpected message during TLS connection negotiation",
+			id:      "TLS_UNEXPECTED",
+			desc:    "received unexpected message during TLS connection negotiation",
+			extract: []*FieldExtraction{tlsRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldEquals("file", ""),
 				FieldStartsWith("msg", "http: TLS handshake error"),
 				FieldContains("msg", "local error: tls: unexpected message"),
 			)},
 		&filter{
-			id:   "TLS_TIMOUT",
-			desc: "i/o timeout during tls handshake",
+			id:      "TLS_TIMOUT",
+			desc:    "i/o timeout during tls handshake",
+			extract: []*FieldExtraction{tlsRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldEquals("file", ""),
 				FieldStartsWith("msg", "http: TLS handshake error"),
 				FieldContains("msg", "i/o timeout"),
 			)},
 		&filter{
-			id:   "TLS_EOF",
-			desc: "connection closed during tls handshake",
+			id:      "TLS_EOF",
+			desc:    "connection closed during tls handshake",
+			extract: []*FieldExtraction{tlsRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldEquals("file", ""),
 				FieldStartsWith("msg", "http: TLS handshake error"),
 				FieldContains("msg", "EOF"),
 			)},
 		&filter{
-			id:   "TLS_PEER_RESET",
-			desc: "peer reset connection during tls handshake",
+			id:      "TLS_PEER_RESET",
+			desc:    "peer reset connection during tls handshake",
+			extract: []*FieldExtraction{tlsRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldEquals("file", ""),
 				FieldStartsWith("msg", "http: TLS handshake error"),
 				FieldContains("msg", "read: connection reset by peer"),
 			)},
 		&filter{
-			id:   "TLS_UNSUPPORTED",
-			desc: "client only offered unsupported TLS versions",
+			id:      "TLS_UNSUPPORTED",
+			desc:    "client only offered unsupported TLS versions",
+			extract: []*FieldExtraction{tlsRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldEquals("file", ""),
 				FieldStartsWith("msg", "http: TLS handshake error"),
@@ -120,48 +178,55 @@ func getControllerLogFilters() []LogFilter {
 				),
 			)},
 		&filter{
-			id:   "TLS_LEGACY",
-			desc: "client used the legacy version field to negotiate TLS version",
+			id:      "TLS_LEGACY",
+			desc:    "client used the legacy version field to negotiate TLS version",
+			extract: []*FieldExtraction{tlsRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldEquals("file", ""),
 				FieldStartsWith("msg", "http: TLS handshake error"),
 				FieldContains("msg", "tls: client used the legacy version field"),
 			)},
 		&filter{
-			id:   "TLS_BAD_CERT",
-			desc: "client submitted a bad tls certificate during tls handshake",
+			id:       "TLS_BAD_CERT",
+			desc:     "client submitted a bad tls certificate during tls handshake",
+			severity: SeverityWarn,
+			extract:  []*FieldExtraction{tlsRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldEquals("file", ""),
 				FieldStartsWith("msg", "http: TLS handshake error"),
 				FieldContains("msg", "tls: bad certificate"),
 			)},
 		&filter{
-			id:   "TLS_V301_v303",
-			desc: "during TLS handshake got record with version 301, but expected version 303",
+			id:      "TLS_V301_v303",
+			desc:    "during TLS handshake got record with version 301, but expected version 303",
+			extract: []*FieldExtraction{tlsRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldEquals("file", ""),
 				FieldStartsWith("msg", "http: TLS handshake error"),
 				FieldContains("msg", "tls: received record with version 301 when expecting version 303"),
 			)},
 		&filter{
-			id:   "TLS_BAD_RECORD_MAC",
-			desc: "during TLS handshake got a bad record MAC",
+			id:      "TLS_BAD_RECORD_MAC",
+			desc:    "during TLS handshake got a bad record MAC",
+			extract: []*FieldExtraction{tlsRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldEquals("file", ""),
 				FieldStartsWith("msg", "http: TLS handshake error"),
 				FieldContains("msg", "tls: bad record MAC"),
 			)},
 		&filter{
-			id:   "TLS_NOT_TLS",
-			desc: "during TLS handshake the first record did not look a like TLS handshake",
+			id:      "TLS_NOT_TLS",
+			desc:    "during TLS handshake the first record did not look a like TLS handshake",
+			extract: []*FieldExtraction{tlsRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldEquals("file", ""),
 				FieldStartsWith("msg", "http: TLS handshake error"),
 				FieldContains("msg", "tls: first record does not look like a TLS handshake"),
 			)},
 		&filter{
-			id:   "TLS_UNSUPPORT_APP_PROTOCOLS",
-			desc: "during TLS handshake the client requested unsupport application protocols",
+			id:      "TLS_UNSUPPORT_APP_PROTOCOLS",
+			desc:    "during TLS handshake the client requested unsupport application protocols",
+			extract: []*FieldExtraction{tlsRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldEquals("file", ""),
 				FieldStartsWith("msg", "http: TLS handshake error"),
@@ -172,40 +237,45 @@ func getControllerLogFilters() []LogFilter {
 	// channel
 	result = append(result,
 		&filter{
-			id:   "CHANNEL_TLS_NOT_TLS",
-			desc: "during tls accept, first data does look like a TLS connection",
+			id:      "CHANNEL_TLS_NOT_TLS",
+			desc:    "during tls accept, first data does look like a TLS connection",
+			extract: []*FieldExtraction{helloRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldContains("file", "channel2/classic_listener.go"),
 				FieldContains("msg", "error receiving hello from [tls:"),
 				FieldContains("msg", "tls: first record does not look like a TLS handshake"),
 			)},
 		&filter{
-			id:   "CHANNEL_TLS_EOF",
-			desc: "during tls accept connection closed",
+			id:      "CHANNEL_TLS_EOF",
+			desc:    "during tls accept connection closed",
+			extract: []*FieldExtraction{helloRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldContains("file", "channel2/classic_listener.go"),
 				FieldContains("msg", "error receiving hello from [tls:"),
 				FieldContains("msg", "receive error (EOF)"),
 			)},
 		&filter{
-			id:   "CHANNEL_TLS_NO_CERT",
-			desc: "during tls accept the client did not provide a certificate",
+			id:      "CHANNEL_TLS_NO_CERT",
+			desc:    "during tls accept the client did not provide a certificate",
+			extract: []*FieldExtraction{helloRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldContains("file", "channel2/classic_listener.go"),
 				FieldContains("msg", "error receiving hello from [tls:"),
 				FieldContains("msg", "tls: client didn't provide a certificate"),
 			)},
 		&filter{
-			id:   "CHANNEL_ACCEPT_PEER_RESET",
-			desc: "during accept the client reset the connection",
+			id:      "CHANNEL_ACCEPT_PEER_RESET",
+			desc:    "during accept the client reset the connection",
+			extract: []*FieldExtraction{helloRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldContains("file", "channel2/classic_listener.go"),
 				FieldContains("msg", "error receiving hello from [tls:"),
 				FieldContains("msg", "read: connection reset by peer"),
 			)},
 		&filter{
-			id:   "CHANNEL_ACCEPT_TIMEOUT",
-			desc: "during accept the client connection timed out",
+			id:      "CHANNEL_ACCEPT_TIMEOUT",
+			desc:    "during accept the client connection timed out",
+			extract: []*FieldExtraction{helloRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldContains("file", "channel2/classic_listener.go"),
 				FieldContains("msg", "error receiving hello from [tls:"),
@@ -223,8 +293,9 @@ func getControllerLogFilters() []LogFilter {
 				FieldMatches("msg", "received.*confirmation request"),
 			)},
 		&filter{
-			id:   "IDLE_CIRCUIT_UNROUTE",
-			desc: "sent an unroute in response to a idle circuit notification for an invalid circuit",
+			id:      "IDLE_CIRCUIT_UNROUTE",
+			desc:    "sent an unroute in response to a idle circuit notification for an invalid circuit",
+			extract: []*FieldExtraction{FieldCaptures("msg", `^sent unroute to \[r/(?P<routerId>[^\]]+)\] for circuit \[s/(?P<circuitId>[^\]]+)\]`)},
 			LogMatcher: AndMatchers(
 				FieldMatches("file", "handler_ctrl/.*_confirmation.go"),
 				FieldStartsWith("msg", "sent unroute to "),
@@ -241,63 +312,103 @@ func getControllerLogFilters() []LogFilter {
 				FieldStartsWith("msg", "network fault processing for "),
 			)},
 		&filter{
-			id:   "FORWARDING_FAULT_REROUTE_ERR",
-			desc: "error while rerouting circuit which had forwarding fault",
+			id:       "FORWARDING_FAULT_REROUTE_ERR",
+			desc:     "error while rerouting circuit which had forwarding fault",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/fault.go"),
 				FieldStartsWith("msg", "error rerouting "),
 			)},
 		&filter{
-			id:   "FORWARDING_FAULT_REROUTE_OK",
-			desc: "rerouted a circuit in response to a fault",
+			id:      "FORWARDING_FAULT_REROUTE_OK",
+			desc:    "rerouted a circuit in response to a fault",
+			extract: []*FieldExtraction{FieldCaptures("msg", `^rerouted \[s/(?P<circuitId>[^\]]+)\] in response to forwarding fault from \[r/(?P<routerId>[^\]]+)\]`)},
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/fault.go"),
 				FieldMatches("msg", "rerouted.*in response to forwarding fault from"),
 			)},
 		&filter{
-			id:   "FORWARDING_FAULT_UNROUTE",
-			desc: "sent an unroute in response to a forwarding fault",
+			id:      "FORWARDING_FAULT_UNROUTE",
+			desc:    "sent an unroute in response to a forwarding fault",
+			extract: []*FieldExtraction{FieldCaptures("msg", `^sent unroute for \[s/(?P<circuitId>[^\]]+)\] to \[r/(?P<routerId>[^\]]+)\]`)},
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/fault.go"),
 				FieldStartsWith("msg", "sent unroute for "),
 			)},
 	)
 
+	// terminators
+	result = append(result,
+		&filter{
+			id:     "TERMINATOR_CREATED",
+			desc:   "a router created a terminator for a service",
+			shared: true,
+			LogMatcher: AndMatchers(
+				FieldContains("file", "create_terminator"),
+				FieldStartsWith("msg", "created terminator"),
+			)},
+		&filter{
+			id:     "TERMINATOR_UPDATED",
+			desc:   "a router updated a terminator, usually to change precedence or cost",
+			shared: true,
+			LogMatcher: AndMatchers(
+				FieldContains("file", "update_terminator"),
+				FieldStartsWith("msg", "updated terminator"),
+			)},
+		&filter{
+			id:     "TERMINATOR_REMOVED",
+			desc:   "a router removed a terminator",
+			shared: true,
+			LogMatcher: AndMatchers(
+				FieldContains("file", "remove_terminator"),
+				FieldStartsWith("msg", "removed terminator"),
+			)},
+	)
+
 	// links
 	result = append(result,
 		&filter{
-			id:   "LINK_FAULT",
-			desc: "received link fault from a router",
+			id:       "LINK_FAULT",
+			desc:     "received link fault from a router",
+			severity: SeverityError,
+			extract:  []*FieldExtraction{FieldCaptures("msg", `^link fault for \[l/(?P<linkId>[^\]]+)\]`)},
 			LogMatcher: AndMatchers(
 				FieldContains("file", "handler_ctrl/fault.go"),
 				FieldStartsWith("msg", "link fault"),
 			)},
 		&filter{
-			id:   "LINK_REROUTE",
-			desc: "routing circuits using a link, after link fault",
+			id:      "LINK_REROUTE",
+			desc:    "routing circuits using a link, after link fault",
+			extract: []*FieldExtraction{linkIdRef},
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/network.go"),
 				FieldStartsWith("msg", "changed link"),
 			)},
 		&filter{
-			id:   "LINK_REROUTE2", // removed post 0.24.2
-			desc: "rerouting a link after it faulted",
+			id:         "LINK_REROUTE2",
+			desc:       "rerouting a link after it faulted",
+			maxVersion: "0.24.2",
+			extract:    []*FieldExtraction{linkIdRef},
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/network.go"),
 				FieldContains("func", "rerouteLink"),
 				FieldMatches("msg", "link.*changed"),
 			)},
 		&filter{
-			id:   "LINK_FAILED",
-			desc: "a router notified us that a link failed",
+			id:       "LINK_FAILED",
+			desc:     "a router notified us that a link failed",
+			severity: SeverityError,
+			extract:  []*FieldExtraction{linkIdRef},
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/network.go"),
 				FieldContains("func", "LinkConnected"),
 				FieldMatches("msg", "link.*failed"),
 			)},
 		&filter{
-			id:   "LINK_REMOVED",
-			desc: "removing a link that's been failed long enough that it hit the threshold (30s)",
+			id:       "LINK_REMOVED",
+			desc:     "removing a link that's been failed long enough that it hit the threshold (30s)",
+			severity: SeverityWarn,
+			extract:  []*FieldExtraction{linkIdRef},
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/assembly.go"),
 				FieldContains("func", "clean"),
@@ -308,15 +419,17 @@ func getControllerLogFilters() []LogFilter {
 	// circuit creation/routing
 	result = append(result,
 		&filter{
-			id:   "LATE_ROUTE_RESPONSE",
-			desc: "a route response received for unknown route, most likely because the route had already timed out",
+			id:       "LATE_ROUTE_RESPONSE",
+			desc:     "a route response received for unknown route, most likely because the route had already timed out",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/routesender.go"),
 				FieldMatches("msg", "received successful route status from.*for alien attempt"),
 			)},
 		&filter{
-			id:   "CIRCUIT_CREATE_FAILED",
-			desc: "circuit creation failed, giving up after 3 attempts and cleaning up any partially established routes",
+			id:       "CIRCUIT_CREATE_FAILED",
+			desc:     "circuit creation failed, giving up after 3 attempts and cleaning up any partially established routes",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/network.go"),
 				FieldContains("msg", "creation failed after [3] attempts, sending cleanup unroutes"),
@@ -329,53 +442,63 @@ func getControllerLogFilters() []LogFilter {
 				FieldStartsWith("msg", "rerouting "),
 			)},
 		&filter{
-			id:   "REROUTE_CIRCUIT_OK",
-			desc: "rerouted circuit in response smart routing, link failure or forwarding failure",
+			id:      "REROUTE_CIRCUIT_OK",
+			desc:    "rerouted circuit in response smart routing, link failure or forwarding failure",
+			extract: []*FieldExtraction{FieldCaptures("msg", `^rerouted \[s/(?P<circuitId>[^\]]+)\]`)},
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/network.go"),
 				FieldStartsWith("msg", "rerouted "),
 			)},
 		&filter{
-			id:   "ROUTE_TIMEOUT",
-			desc: "a routing attempt failed due to a timeout",
+			id:       "ROUTE_TIMEOUT",
+			desc:     "a routing attempt failed due to a timeout",
+			severity: SeverityError,
+			shared:   true,
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/network.go"),
 				FieldMatches("msg", `route attempt.*failed \(timeout creating routes`),
 			)},
 		&filter{
-			id:   "CIRCUIT_CREATE_ERR_BAD_SESSION",
-			desc: "circuit creation failed because an invalid session was provided",
+			id:       "CIRCUIT_CREATE_ERR_BAD_SESSION",
+			desc:     "circuit creation failed because an invalid session was provided",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldContains("file", "handler_edge_ctrl/common.go"),
 				FieldEquals("error", "Invalid Session"),
 				FieldStartsWith("msg", "responded with error"),
 			)},
-		// redundant?
+		// older wording of the above, removed post 0.24.2
 		&filter{
-			id:   "CIRCUIT_CREATE_ERR_BAD_SESSION-2",
-			desc: "when creating a circuit an invalid session was provided",
+			id:         "CIRCUIT_CREATE_ERR_BAD_SESSION-2",
+			desc:       "when creating a circuit an invalid session was provided",
+			severity:   SeverityWarn,
+			maxVersion: "0.24.2",
 			LogMatcher: AndMatchers(
 				FieldContains("file", "handler_edge_ctrl/common.go"),
 				FieldEquals("msg", "invalid session"),
 			)},
 		&filter{
-			id:   "CIRCUIT_CREATE_ERR_NO_ROUTE",
-			desc: "circuit could not be created because the terminating router failed to dial the server with the error 'no route to host'",
+			id:       "CIRCUIT_CREATE_ERR_NO_ROUTE",
+			desc:     "circuit could not be created because the terminating router failed to dial the server with the error 'no route to host'",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/network.go"),
 				FieldMatches("msg", "route attempt.*failed.*connect: no route to host"),
 			)},
-		// redundant?
+		// older wording of the above, removed post 0.24.2
 		&filter{
-			id:   "CIRCUIT_CREATE_ERR_NO_ROUTE-2",
-			desc: "circuit could not be created because the terminating router failed to dial the server with the error 'no route to host'",
+			id:         "CIRCUIT_CREATE_ERR_NO_ROUTE-2",
+			desc:       "circuit could not be created because the terminating router failed to dial the server with the error 'no route to host'",
+			severity:   SeverityWarn,
+			maxVersion: "0.24.2",
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/routesender.go"),
 				FieldMatches("msg", "received failed route status.*connect: no route to host"),
 			)},
 		&filter{
-			id:   "CIRCUIT_CREATE_ERR_NO_PATH",
-			desc: "circuit creation failed because no route existed for the service",
+			id:       "CIRCUIT_CREATE_ERR_NO_PATH",
+			desc:     "circuit creation failed because no route existed for the service",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldContains("file", "handler_edge_ctrl/common.go"),
 				FieldEquals("msg", "responded with error"),
@@ -383,39 +506,46 @@ func getControllerLogFilters() []LogFilter {
 				FieldContains("error", "source unreachable"),
 			)},
 		&filter{
-			id:   "CIRCUIT_CREATE_ERR_NO_TERMINATORS",
-			desc: "circuit creation failed because the service has no terminators",
+			id:       "CIRCUIT_CREATE_ERR_NO_TERMINATORS",
+			desc:     "circuit creation failed because the service has no terminators",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldContains("file", "handler_edge_ctrl/common.go"),
 				FieldEquals("msg", "responded with error"),
 				FieldContains("error", "has no terminators"),
 			)},
 		&filter{
-			id:   "CIRCUIT_CREATE_ERR_CONN_REFUSED",
-			desc: "circuit could not be created because the terminating router failed to dial the server with the error 'connection refused'",
+			id:       "CIRCUIT_CREATE_ERR_CONN_REFUSED",
+			desc:     "circuit could not be created because the terminating router failed to dial the server with the error 'connection refused'",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/network.go"),
 				FieldMatches("msg", "route attempt.*failed.*connect: connection refused"),
 			)},
-		// redundant
+		// older wording of the above, removed post 0.24.2
 		&filter{
-			id:   "CIRCUIT_CREATE_ERR_CONN_REFUSED-2",
-			desc: "circuit could not be created because the terminating router failed to dial the server with the error 'connection refused'",
+			id:         "CIRCUIT_CREATE_ERR_CONN_REFUSED-2",
+			desc:       "circuit could not be created because the terminating router failed to dial the server with the error 'connection refused'",
+			severity:   SeverityWarn,
+			maxVersion: "0.24.2",
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/routesender.go"),
 				FieldMatches("msg", "received failed route status.*connect: connection refused"),
 			)},
 		&filter{
-			id:   "CIRCUIT_CREATE_ERR_IO_TIMEOUT",
-			desc: "circuit could not be created because the terminating router failed to dial the server with the error 'i/o timeout'",
+			id:       "CIRCUIT_CREATE_ERR_IO_TIMEOUT",
+			desc:     "circuit could not be created because the terminating router failed to dial the server with the error 'i/o timeout'",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/network.go"),
 				FieldMatches("msg", "route attempt.*failed.*dial.*: i/o timeout"),
 			)},
-		// redundant
+		// older wording of the above, removed post 0.24.2
 		&filter{
-			id:   "CIRCUIT_CREATE_ERR_IO_TIMEOUT-2",
-			desc: "circuit could not be created because the terminating router failed to dial the server with the error 'i/o timeout'",
+			id:         "CIRCUIT_CREATE_ERR_IO_TIMEOUT-2",
+			desc:       "circuit could not be created because the terminating router failed to dial the server with the error 'i/o timeout'",
+			severity:   SeverityWarn,
+			maxVersion: "0.24.2",
 			LogMatcher: AndMatchers(
 				FieldContains("file", "network/routesender.go"),
 				FieldMatches("msg", "received failed route status.*dial.*: i/o timeout"),
@@ -425,31 +555,35 @@ func getControllerLogFilters() []LogFilter {
 	// misc
 	result = append(result,
 		&filter{
-			id:   "ROUTER_ALREADY_CONNECTED",
-			desc: "router tried to connected but a router with that id is already connected",
+			id:       "ROUTER_ALREADY_CONNECTED",
+			desc:     "router tried to connected but a router with that id is already connected",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldContains("file", "channel2/classic_listener.go"),
 				FieldContains("msg", "connection handler error"),
 				FieldContains("msg", "router already connected"),
 			)},
 		&filter{
-			id:   "ROUTER_UNENROLLED",
-			desc: "router tried to connected but either the router doesn't exist or the fingerprint didn't match",
+			id:       "ROUTER_UNENROLLED",
+			desc:     "router tried to connected but either the router doesn't exist or the fingerprint didn't match",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldContains("file", "channel2/classic_listener.go"),
 				FieldContains("msg", "connection handler error"),
 				FieldContains("msg", "unenrolled router"),
 			)},
 		&filter{
-			id:   "ROUTER_NOT_TUNNELER",
-			desc: "router is trying to use embedded tunneler functionality but the router is not configured as a tunneler",
+			id:       "ROUTER_NOT_TUNNELER",
+			desc:     "router is trying to use embedded tunneler functionality but the router is not configured as a tunneler",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldEquals("error", "tunneling not enabled"),
 				FieldContains("file", "handler_edge_ctrl"),
 			)},
 		&filter{
-			id:   "REST_RESPONSE_TIMEOUT",
-			desc: "while trying to respond to an http request, the handler timed out",
+			id:       "REST_RESPONSE_TIMEOUT",
+			desc:     "while trying to respond to an http request, the handler timed out",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				OrMatchers(
 					FieldContains("file", "response/responder.go"),
@@ -460,16 +594,18 @@ func getControllerLogFilters() []LogFilter {
 			)},
 		// move to debug? Can probably detect not found
 		&filter{
-			id:   "POSTURE_CHECK_FAIL_SESSION_DELETE_ERR",
-			desc: "failed to delete a session after posture check failure b/c the session was already deleted",
+			id:       "POSTURE_CHECK_FAIL_SESSION_DELETE_ERR",
+			desc:     "failed to delete a session after posture check failure b/c the session was already deleted",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldContains("file", "model/posture_response_model.go"),
 				FieldMatches("msg", "error removing session.*due to posture check failure.*"),
 				FieldMatches("error", "session with id.*not found"),
 			)},
 		&filter{
-			id:   "TUNNEL_BAD_SESSION",
-			desc: "tunnel provided session doesn't match existing api session or service",
+			id:       "TUNNEL_BAD_SESSION",
+			desc:     "tunnel provided session doesn't match existing api session or service",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldContains("file", "common_tunnel.go"),
 				FieldStartsWith("msg", "required session did not match service or api session"),
@@ -483,19 +619,53 @@ func getControllerLogFilters() []LogFilter {
 				FieldStartsWith("msg", "snapshotting database"),
 			)},
 		&filter{
-			id:   "XMGMT_CLOSED", // removed to debug post 0.24.2
-			desc: "a management channel connection was closed",
+			id:         "XMGMT_CLOSED", // moved to debug
+			desc:       "a management channel connection was closed",
+			maxVersion: "0.24.2",
 			LogMatcher: AndMatchers(
 				FieldContains("file", "handler_mgmt/close.go"),
 				FieldStartsWith("msg", "closing Xmgmt instances for"),
 			)},
 	)
 
+	// process lifecycle
+	result = append(result,
+		&filter{
+			id:         "PROCESS_START",
+			desc:       "the controller process started and logged its version",
+			shared:     true,
+			LogMatcher: FieldStartsWith("msg", "starting ziti-controller"),
+		},
+		&filter{
+			id:         "PROCESS_MODULE_VERSION",
+			desc:       "the controller logged the version of an openziti module it was built with, after starting",
+			shared:     true,
+			LogMatcher: FieldMatches("msg", `^ziti-[\w\-]+ version`),
+		},
+		&filter{
+			id:         "SYSTEMD_STARTED",
+			desc:       "systemd reported that it started a unit",
+			shared:     true,
+			LogMatcher: FieldStartsWith("systemd", "Started "),
+		},
+		&filter{
+			id:       "SYSTEMD_STOPPED",
+			desc:     "systemd reported that it stopped a unit or that the unit's process exited",
+			severity: SeverityWarn,
+			shared:   true,
+			LogMatcher: OrMatchers(
+				FieldStartsWith("systemd", "Stopped "),
+				FieldContains("systemd", "Main process exited"),
+			)},
+	)
+
 	// panics
 	result = append(result,
 		&filter{
 			id:         "PANIC_UNKNOWN",
 			desc:       "uncategorized panic",
+			severity:   SeverityCritical,
+			shared:     true,
 			LogMatcher: FieldContains("nonJson", "panic"),
 		},
 	)
@@ -511,11 +681,15 @@ func (self *ControllerLogs) summarize(cmd *cobra.Command, args []string) error {
 		bucketSize:                  self.bucketSize,
 		bucketMatches:               map[LogFilter]int{},
 		maxUnmatchedLoggedPerBucket: self.maxUnmatched,
-		ignore:                      self.ignore,
+		selector:                    self.selector,
 		formatter:                   self.formatter,
+		minSeverity:                 self.minSeverity,
+		color:                       useColor(),
+		groupBy:                     self.groupBy,
+		groupLimit:                  self.groupLimit,
 	}
 
-	return ScanJsonLines(args[0], self.processLogEntry)
+	return self.scan(args)
 }
 
 func (self *ControllerLogs) filter(cmd *cobra.Command, args []string) error {
@@ -525,8 +699,12 @@ func (self *ControllerLogs) filter(cmd *cobra.Command, args []string) error {
 
 	self.handler = &LogFilterHandler{
 		maxUnmatched: self.maxUnmatched,
-		include:      self.includeFilters,
+		selector:     self.selector,
+		hasSelection: len(self.includeFilters) > 0 || len(self.ignore) > 0,
+		printAll:     self.where != "" && len(self.includeFilters) == 0 && len(self.ignore) == 0 && self.minSeverity == nil,
+		minSeverity:  self.minSeverity,
+		color:        useColor(),
 	}
 
-	return ScanJsonLines(args[0], self.processLogEntry)
+	return self.scan(args)
 }
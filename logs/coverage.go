@@ -0,0 +1,231 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// coverageCount tracks how many entries were seen for a level or source file, and how many of those were matched
+type coverageCount struct {
+	Name        string  `json:"name"`
+	Entries     int     `json:"entries"`
+	Categorized int     `json:"categorized"`
+	Coverage    float64 `json:"coverage"`
+}
+
+func (self *coverageCount) add(matched bool) {
+	self.Entries++
+	if matched {
+		self.Categorized++
+	}
+	self.Coverage = 100 * float64(self.Categorized) / float64(self.Entries)
+}
+
+type neverMatchedFilter struct {
+	Id       string `json:"id"`
+	Desc     string `json:"desc"`
+	Versions string `json:"versions,omitempty"`
+	Active   bool   `json:"active"`
+}
+
+// CoverageHandler counts the entries matched by any filter, overall, per level and per source file, along with which
+// filters matched at least once
+type CoverageHandler struct {
+	total     coverageCount
+	levels    map[string]*coverageCount
+	files     map[string]*coverageCount
+	matched   map[string]struct{}
+	formatter string
+}
+
+func NewCoverageHandler(formatter string) *CoverageHandler {
+	return &CoverageHandler{
+		total:     coverageCount{Name: "total"},
+		levels:    map[string]*coverageCount{},
+		files:     map[string]*coverageCount{},
+		matched:   map[string]struct{}{},
+		formatter: formatter,
+	}
+}
+
+func (self *CoverageHandler) HandleNewLine(*JsonParseContext) error {
+	return nil
+}
+
+func (self *CoverageHandler) HandleMatch(ctx *JsonParseContext, logFilter LogFilter) error {
+	self.matched[logFilter.Id()] = struct{}{}
+	self.add(ctx, true)
+	return nil
+}
+
+func (self *CoverageHandler) HandleUnmatched(ctx *JsonParseContext) error {
+	self.add(ctx, false)
+	return nil
+}
+
+// HandleEnd does nothing, as the report needs the filter versions, which are only final once the scan is complete
+func (self *CoverageHandler) HandleEnd(*JsonParseContext) {}
+
+func (self *CoverageHandler) add(ctx *JsonParseContext, matched bool) {
+	level, file := "(none)", "(none)"
+	switch {
+	case ctx.entry != nil:
+		if v := ctx.GetString("level"); v != "" {
+			level = v
+		}
+		if v := ctx.GetString("file"); v != "" {
+			file = getSourceFile(v)
+		}
+	case ctx.systemd != nil:
+		level, file = "(systemd)", "(systemd)"
+	default:
+		level, file = "(non-json)", "(non-json)"
+	}
+
+	self.total.add(matched)
+	getCoverageCount(self.levels, level).add(matched)
+	getCoverageCount(self.files, file).add(matched)
+}
+
+func getCoverageCount(m map[string]*coverageCount, name string) *coverageCount {
+	result, found := m[name]
+	if !found {
+		result = &coverageCount{Name: name}
+		m[name] = result
+	}
+	return result
+}
+
+// sortCoverageCounts returns the counts with the most uncategorized entries first
+func sortCoverageCounts(m map[string]*coverageCount) []*coverageCount {
+	result := []*coverageCount{}
+	for _, v := range m {
+		result = append(result, v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		iMissed := result[i].Entries - result[i].Categorized
+		jMissed := result[j].Entries - result[j].Categorized
+		if iMissed != jMissed {
+			return iMissed > jMissed
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func (self *CoverageHandler) getNeverMatched(parser *JsonLogsParser) []*neverMatchedFilter {
+	result := []*neverMatchedFilter{}
+	for _, logFilter := range parser.filters {
+		if _, found := self.matched[logFilter.Id()]; found {
+			continue
+		}
+		versions := parser.versionRanges[logFilter]
+		result = append(result, &neverMatchedFilter{
+			Id:       logFilter.Id(),
+			Desc:     logFilter.Desc(),
+			Versions: versions.String(),
+			Active:   versions.contains(parser.version),
+		})
+	}
+	return result
+}
+
+// report outputs the coverage, using the parser to determine which of the filters that never matched were active
+// for the ziti version in the logs
+func (self *CoverageHandler) report(parser *JsonLogsParser) {
+	if self.formatter == "json" {
+		self.reportJson(parser)
+	} else {
+		self.reportText(parser)
+	}
+}
+
+func (self *CoverageHandler) reportText(parser *JsonLogsParser) {
+	if parser.version != nil {
+		fmt.Printf("ziti version: %v\n", parser.version)
+	}
+	fmt.Printf("entries: %v, categorized: %v (%.1f%%)\n\n", self.total.Entries, self.total.Categorized, self.total.Coverage)
+
+	self.printCounts("LEVEL", sortCoverageCounts(self.levels))
+	self.printCounts("FILE", sortCoverageCounts(self.files))
+
+	neverMatched := self.getNeverMatched(parser)
+	fmt.Printf("never matched filters: %v of %v\n", len(neverMatched), len(parser.filters))
+	for _, f := range neverMatched {
+		switch {
+		case !f.Active:
+			fmt.Printf("    %v (%v, not active for %v)\n", f.Id, f.Versions, parser.version)
+		case f.Versions != "":
+			fmt.Printf("    %v (%v)\n", f.Id, f.Versions)
+		default:
+			fmt.Printf("    %v\n", f.Id)
+		}
+	}
+}
+
+func (self *CoverageHandler) printCounts(title string, counts []*coverageCount) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "%v\tENTRIES\tCATEGORIZED\tCOVERAGE\n", title)
+	for _, count := range counts {
+		_, _ = fmt.Fprintf(w, "%v\t%v\t%v\t%.1f%%\n", count.Name, count.Entries, count.Categorized, count.Coverage)
+	}
+	_ = w.Flush()
+	fmt.Println()
+}
+
+func (self *CoverageHandler) reportJson(parser *JsonLogsParser) {
+	model := map[string]interface{}{
+		"entries":      self.total.Entries,
+		"categorized":  self.total.Categorized,
+		"coverage":     self.total.Coverage,
+		"levels":       sortCoverageCounts(self.levels),
+		"files":        sortCoverageCounts(self.files),
+		"neverMatched": self.getNeverMatched(parser),
+	}
+	if parser.version != nil {
+		model["version"] = parser.version.String()
+	}
+
+	j, err := json.Marshal(model)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s\n", string(j))
+}
+
+func (self *JsonLogsParser) showCoverage(_ *cobra.Command, args []string) error {
+	if err := self.validate(); err != nil {
+		return err
+	}
+
+	handler := NewCoverageHandler(self.formatter)
+	self.handler = handler
+
+	if err := self.scan(args); err != nil {
+		return err
+	}
+	handler.report(self)
+	return nil
+}
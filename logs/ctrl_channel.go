@@ -0,0 +1,244 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type ctrlChannelEpisode struct {
+	Start          time.Time `json:"start"`
+	End            time.Time `json:"end"`
+	FailedAttempts int       `json:"failedAttempts"`
+	FailedPings    int       `json:"failedPings"`
+	Outage         string    `json:"outage"`
+	Ongoing        bool      `json:"ongoing,omitempty"`
+	outage         time.Duration
+}
+
+type ctrlChannelDowntime struct {
+	Day      string `json:"day"`
+	Episodes int    `json:"episodes"`
+	Downtime string `json:"downtime"`
+	downtime time.Duration
+}
+
+// CtrlChannelTimelineHandler groups control channel reconnect messages into disconnect episodes. An episode starts
+// with a failed ping or the start of the reconnect process and ends when the channel reconnects or the controller
+// sends a new hello
+type CtrlChannelTimelineHandler struct {
+	current   *ctrlChannelEpisode
+	episodes  []*ctrlChannelEpisode
+	lastSeen  time.Time
+	location  *time.Location
+	formatter string
+}
+
+// NewCtrlChannelTimelineHandler returns a handler which shows episode times, and splits downtime into days, in the
+// given location
+func NewCtrlChannelTimelineHandler(formatter string, location *time.Location) *CtrlChannelTimelineHandler {
+	return &CtrlChannelTimelineHandler{
+		location:  location,
+		formatter: formatter,
+	}
+}
+
+func (self *CtrlChannelTimelineHandler) HandleNewLine(ctx *JsonParseContext) error {
+	if t, err := ctx.GetTime(); err == nil && t.After(self.lastSeen) {
+		self.lastSeen = t
+	}
+	return nil
+}
+
+func (self *CtrlChannelTimelineHandler) HandleMatch(ctx *JsonParseContext, logFilter LogFilter) error {
+	id := logFilter.Id()
+	switch id {
+	case "CTRL_CH_RECONNECT_START", "CTRL_CH_RECONNECT_PING_FAILED", "CTRL_CH_RECONNECT_PING_ERR",
+		"CTRL_CH_RECONNECT_ERR", "CTRL_CH_RECONNECT_OK", "CTRL_CH_EDGE_HELLO":
+	default:
+		return nil
+	}
+
+	t, err := ctx.GetTime()
+	if err != nil {
+		return err
+	}
+
+	switch id {
+	case "CTRL_CH_RECONNECT_START":
+		self.startEpisode(t)
+	case "CTRL_CH_RECONNECT_PING_FAILED", "CTRL_CH_RECONNECT_PING_ERR":
+		// a failed ping from the ping loop also starts a reconnect. Other ping loop messages aren't failures
+		self.startEpisode(t)
+		self.current.FailedPings++
+	case "CTRL_CH_RECONNECT_ERR":
+		self.startEpisode(t)
+		self.current.FailedAttempts++
+	case "CTRL_CH_RECONNECT_OK", "CTRL_CH_EDGE_HELLO":
+		self.endEpisode(t)
+	}
+	return nil
+}
+
+func (self *CtrlChannelTimelineHandler) startEpisode(t time.Time) {
+	if self.current == nil {
+		self.current = &ctrlChannelEpisode{Start: t.In(self.location)}
+	}
+}
+
+func (self *CtrlChannelTimelineHandler) endEpisode(t time.Time) {
+	if self.current != nil {
+		self.current.End = t.In(self.location)
+		self.episodes = append(self.episodes, self.current)
+		self.current = nil
+	}
+}
+
+func (self *CtrlChannelTimelineHandler) HandleUnmatched(*JsonParseContext) error {
+	return nil
+}
+
+func (self *CtrlChannelTimelineHandler) HandleEnd(*JsonParseContext) {
+	if self.current != nil {
+		self.current.Ongoing = true
+		self.endEpisode(self.lastSeen)
+	}
+
+	dayList, total := self.getDowntimeByDay()
+	if self.formatter == "json" {
+		self.dumpJson(dayList, total)
+	} else {
+		self.dumpText(dayList, total)
+	}
+}
+
+// getDowntimeByDay returns the downtime for each day, along with the total. Days are calendar days in the --timezone
+// location, the same as the episode times, so an outage in the evening isn't split at UTC midnight
+func (self *CtrlChannelTimelineHandler) getDowntimeByDay() ([]*ctrlChannelDowntime, time.Duration) {
+	var total time.Duration
+	days := map[string]*ctrlChannelDowntime{}
+	for _, episode := range self.episodes {
+		if episode.End.Before(episode.Start) {
+			episode.End = episode.Start
+		}
+		episode.outage = episode.End.Sub(episode.Start)
+		episode.Outage = episode.outage.String()
+		total += episode.outage
+
+		// attribute downtime to each day the episode spans
+		start := episode.Start
+		for {
+			dayStart := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, self.location)
+			dayEnd := dayStart.AddDate(0, 0, 1)
+			end := episode.End
+			if end.After(dayEnd) {
+				end = dayEnd
+			}
+
+			key := dayStart.Format(time.DateOnly)
+			day, found := days[key]
+			if !found {
+				day = &ctrlChannelDowntime{Day: key}
+				days[key] = day
+			}
+			if start.Equal(episode.Start) {
+				day.Episodes++
+			}
+			day.downtime += end.Sub(start)
+
+			if !episode.End.After(dayEnd) {
+				break
+			}
+			start = dayEnd
+		}
+	}
+
+	var dayList []*ctrlChannelDowntime
+	for _, day := range days {
+		day.Downtime = day.downtime.String()
+		dayList = append(dayList, day)
+	}
+	sort.Slice(dayList, func(i, j int) bool {
+		return dayList[i].Day < dayList[j].Day
+	})
+	return dayList, total
+}
+
+func (self *CtrlChannelTimelineHandler) dumpText(days []*ctrlChannelDowntime, total time.Duration) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "START\tEND\tFAILED ATTEMPTS\tFAILED PINGS\tOUTAGE")
+	for _, episode := range self.episodes {
+		end := episode.End.Format(time.RFC3339)
+		if episode.Ongoing {
+			end += " (ongoing)"
+		}
+		_, _ = fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", episode.Start.Format(time.RFC3339), end,
+			episode.FailedAttempts, episode.FailedPings, episode.Outage)
+	}
+	_ = w.Flush()
+
+	fmt.Println()
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "DAY\tEPISODES\tDOWNTIME")
+	for _, day := range days {
+		_, _ = fmt.Fprintf(w, "%v\t%v\t%v\n", day.Day, day.Episodes, day.Downtime)
+	}
+	_ = w.Flush()
+
+	fmt.Printf("\ntotal downtime: %v over %v episodes\n", total, len(self.episodes))
+}
+
+func (self *CtrlChannelTimelineHandler) dumpJson(days []*ctrlChannelDowntime, total time.Duration) {
+	episodes := self.episodes
+	if episodes == nil {
+		episodes = []*ctrlChannelEpisode{}
+	}
+	if days == nil {
+		days = []*ctrlChannelDowntime{}
+	}
+
+	model := map[string]interface{}{
+		"episodes":      episodes,
+		"days":          days,
+		"totalDowntime": total.String(),
+	}
+
+	j, err := json.Marshal(model)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s\n", string(j))
+}
+
+func (self *JsonLogsParser) showCtrlChannel(_ *cobra.Command, args []string) error {
+	if err := self.validate(); err != nil {
+		return err
+	}
+
+	self.handler = NewCtrlChannelTimelineHandler(self.formatter, self.location)
+
+	return self.scan(args)
+}
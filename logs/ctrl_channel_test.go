@@ -0,0 +1,98 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCtrlChannelDowntimeByDay checks that an evening outage is reported under its day in the --timezone location,
+// rather than split at UTC midnight, and that a failed ping from the ping loop starts an episode while its other messages don't
+func TestCtrlChannelDowntimeByDay(t *testing.T) {
+	parser := newTestParser(t, "router")
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewCtrlChannelTimelineHandler("json", location)
+	parser.handler = handler
+
+	lines := `{"file":"github.com/openziti/foundation@v0.15.0/channel2/reconnecting_impl.go:101","func":"github.com/openziti/foundation/channel2.(*reconnectingImpl).pingInstance","level":"info","msg":"starting","time":"2024-03-01T17:00:00-05:00"}
+{"file":"github.com/openziti/foundation@v0.15.0/channel2/reconnecting_impl.go:113","func":"github.com/openziti/foundation/channel2.(*reconnectingImpl).pingInstance","level":"error","msg":"ping failed, reconnecting","time":"2024-03-01T18:00:00-05:00"}
+{"file":"github.com/openziti/foundation@v0.15.0/channel2/reconnecting_dialer.go:95","level":"error","msg":"reconnection attempt [1] failed","time":"2024-03-01T18:30:00-05:00"}
+{"file":"github.com/openziti/foundation@v0.15.0/channel2/reconnecting_impl.go:86","level":"info","msg":"reconnected","time":"2024-03-01T20:00:00-05:00"}
+`
+	if err := parser.scanLines(lines); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(handler.episodes) != 1 {
+		t.Fatalf("expected 1 episode, got %v", len(handler.episodes))
+	}
+	if episode := handler.episodes[0]; episode.FailedPings != 1 || episode.FailedAttempts != 1 {
+		t.Errorf("expected 1 failed ping and 1 failed attempt, got %v and %v", episode.FailedPings, episode.FailedAttempts)
+	}
+
+	days, total := handler.getDowntimeByDay()
+	if len(days) != 1 {
+		t.Fatalf("expected 1 day, got %v", len(days))
+	}
+	if days[0].Day != "2024-03-01" || days[0].Downtime != "2h0m0s" || total.String() != "2h0m0s" {
+		t.Errorf("expected 2h0m0s on 2024-03-01, got %v on %v, %v in total", days[0].Downtime, days[0].Day, total)
+	}
+}
+
+// TestCtrlChannelDowntimeAcrossMidnight checks that an outage which crosses midnight in the --timezone location is
+// split at that midnight, even though the entries are logged in UTC
+func TestCtrlChannelDowntimeAcrossMidnight(t *testing.T) {
+	parser := newTestParser(t, "router")
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewCtrlChannelTimelineHandler("json", location)
+	parser.handler = handler
+
+	lines := `{"file":"github.com/openziti/foundation@v0.15.0/channel2/reconnecting_impl.go:113","func":"github.com/openziti/foundation/channel2.(*reconnectingImpl).pingInstance","level":"error","msg":"ping failed, reconnecting","time":"2024-03-02T03:00:00Z"}
+{"file":"github.com/openziti/foundation@v0.15.0/channel2/reconnecting_impl.go:86","level":"info","msg":"reconnected","time":"2024-03-02T07:30:00Z"}
+`
+	if err = parser.scanLines(lines); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(handler.episodes) != 1 {
+		t.Fatalf("expected 1 episode, got %v", len(handler.episodes))
+	}
+	if start := handler.episodes[0].Start.Format(time.RFC3339); start != "2024-03-01T22:00:00-05:00" {
+		t.Errorf("expected the episode to start at 2024-03-01T22:00:00-05:00, got %v", start)
+	}
+
+	days, total := handler.getDowntimeByDay()
+	if len(days) != 2 {
+		t.Fatalf("expected 2 days, got %v", len(days))
+	}
+	if days[0].Day != "2024-03-01" || days[0].Downtime != "2h0m0s" || days[0].Episodes != 1 {
+		t.Errorf("expected 1 episode and 2h0m0s on 2024-03-01, got %v and %v on %v", days[0].Episodes, days[0].Downtime, days[0].Day)
+	}
+	if days[1].Day != "2024-03-02" || days[1].Downtime != "2h30m0s" || days[1].Episodes != 0 {
+		t.Errorf("expected 0 episodes and 2h30m0s on 2024-03-02, got %v and %v on %v", days[1].Episodes, days[1].Downtime, days[1].Day)
+	}
+	if total.String() != "4h30m0s" {
+		t.Errorf("expected 4h30m0s in total, got %v", total)
+	}
+}
@@ -35,6 +35,7 @@ func NewEndpointLogsCommand() *cobra.Command {
 		Use:     "filter",
 		Short:   "filter endpoint log entries",
 		Aliases: []string{"f"},
+		Args:    cobra.MinimumNArgs(1),
 		RunE:    endpointLogs.filter,
 	}
 
@@ -44,6 +45,7 @@ func NewEndpointLogsCommand() *cobra.Command {
 		Use:     "summarize",
 		Short:   "Show endpoint log entry summaries",
 		Aliases: []string{"s"},
+		Args:    cobra.MinimumNArgs(1),
 		RunE:    endpointLogs.summarize,
 	}
 
@@ -55,10 +57,31 @@ func NewEndpointLogsCommand() *cobra.Command {
 		Use:     "categories",
 		Short:   "Show endpoint log entry categories",
 		Aliases: []string{"cat"},
-		Run:     endpointLogs.ShowCategories,
+		RunE:    endpointLogs.ShowCategories,
 	}
 
-	endpointLogsCmd.AddCommand(filterEndpointLogsCmd, summarizeEndpointLogsCmd, showEndpointLogCategoriesCmd)
+	endpointLogs.addFilterSetArgs(showEndpointLogCategoriesCmd)
+
+	suggestEndpointLogsCmd := &cobra.Command{
+		Use:   "suggest",
+		Short: "Cluster unmatched endpoint log entries into message templates and suggest filters for them",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  endpointLogs.suggestFilters,
+	}
+
+	endpointLogs.addSuggestArgs(suggestEndpointLogsCmd)
+
+	coverageEndpointLogsCmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "Show the percentage of endpoint log entries categorized by filters, per level and source file, and which filters never matched",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  endpointLogs.showCoverage,
+	}
+
+	endpointLogs.addTimelineArgs(coverageEndpointLogsCmd)
+	endpointLogs.addWhereArgs(coverageEndpointLogsCmd)
+
+	endpointLogsCmd.AddCommand(filterEndpointLogsCmd, summarizeEndpointLogsCmd, showEndpointLogCategoriesCmd, suggestEndpointLogsCmd, coverageEndpointLogsCmd)
 
 	return endpointLogsCmd
 }
@@ -68,6 +91,7 @@ type EndpointLogs struct {
 }
 
 func (self *EndpointLogs) Init() {
+	self.component = "endpoint"
 	self.filters = getEndpointLogFilters()
 }
 
@@ -79,6 +103,8 @@ func getEndpointLogFilters() []LogFilter {
 		&filter{
 			id:         "PANIC_UNKNOWN",
 			desc:       "uncategorized panic",
+			severity:   SeverityCritical,
+			shared:     true,
 			LogMatcher: FieldContains("nonJson", "panic"),
 		},
 	)
@@ -94,11 +120,15 @@ func (self *EndpointLogs) summarize(cmd *cobra.Command, args []string) error {
 		bucketSize:                  self.bucketSize,
 		bucketMatches:               map[LogFilter]int{},
 		maxUnmatchedLoggedPerBucket: self.maxUnmatched,
-		ignore:                      self.ignore,
+		selector:                    self.selector,
 		formatter:                   self.formatter,
+		minSeverity:                 self.minSeverity,
+		color:                       useColor(),
+		groupBy:                     self.groupBy,
+		groupLimit:                  self.groupLimit,
 	}
 
-	return ScanJsonLines(args[0], self.processLogEntry)
+	return self.scan(args)
 }
 
 func (self *EndpointLogs) filter(cmd *cobra.Command, args []string) error {
@@ -108,8 +138,12 @@ func (self *EndpointLogs) filter(cmd *cobra.Command, args []string) error {
 
 	self.handler = &LogFilterHandler{
 		maxUnmatched: self.maxUnmatched,
-		include:      self.includeFilters,
+		selector:     self.selector,
+		hasSelection: len(self.includeFilters) > 0 || len(self.ignore) > 0,
+		printAll:     self.where != "" && len(self.includeFilters) == 0 && len(self.ignore) == 0 && self.minSeverity == nil,
+		minSeverity:  self.minSeverity,
+		color:        useColor(),
 	}
 
-	return ScanJsonLines(args[0], self.processLogEntry)
+	return self.scan(args)
 }
@@ -0,0 +1,123 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// FieldExtractor may be implemented by a LogFilter which pulls values out of the entries it matches, such as ids and
+// addresses which ziti only logs inside the message. The values are available from JsonParseContext.GetString to
+// the handlers, under the name of the capture
+type FieldExtractor interface {
+	ExtractFields(ctx *JsonParseContext)
+}
+
+// FieldExtraction sets a virtual field for each named capture in the regex which matches the field's value
+type FieldExtraction struct {
+	field string
+	regex *regexp.Regexp
+	err   error
+}
+
+// FieldCaptures returns an extraction from the given field. If the regex doesn't compile or has no named captures,
+// the error is reported by validateMatcher and the extraction does nothing
+func FieldCaptures(field, expr string) *FieldExtraction {
+	regex, err := regexp.Compile(expr)
+	if err == nil && len(getCaptureNames(regex)) == 0 {
+		err = errors.Errorf("regex '%v' has no named captures, such as (?P<linkId>\\w+)", expr)
+	}
+	return &FieldExtraction{
+		field: field,
+		regex: regex,
+		err:   err,
+	}
+}
+
+func getCaptureNames(regex *regexp.Regexp) []string {
+	var result []string
+	for _, name := range regex.SubexpNames() {
+		if name != "" {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func (self *FieldExtraction) validate() error {
+	if self.err != nil {
+		return errors.Wrapf(self.err, "invalid extraction from field %v", self.field)
+	}
+	return nil
+}
+
+func (self *FieldExtraction) apply(ctx *JsonParseContext) {
+	if self.err != nil {
+		return
+	}
+	match := self.regex.FindStringSubmatch(ctx.GetString(self.field))
+	if match == nil {
+		return
+	}
+	for idx, name := range self.regex.SubexpNames() {
+		if name != "" && match[idx] != "" {
+			ctx.setExtractedField(name, match[idx])
+		}
+	}
+}
+
+func (self *filter) ExtractFields(ctx *JsonParseContext) {
+	for _, extraction := range self.extract {
+		extraction.apply(ctx)
+	}
+}
+
+// setExtractedField sets a virtual field for the current entry. Fields logged by ziti and fields already extracted,
+// by an earlier filter or extraction, take precedence
+func (self *JsonParseContext) setExtractedField(name, value string) {
+	if self.GetString(name) != "" {
+		return
+	}
+	if self.extracted == nil {
+		self.extracted = map[string]string{}
+	}
+	self.extracted[name] = value
+}
+
+// extractFields applies the filter's extractions, if it has any, to the entry it matched
+func extractFields(ctx *JsonParseContext, logFilter LogFilter) {
+	if extractor, ok := logFilter.(FieldExtractor); ok {
+		extractor.ExtractFields(ctx)
+	}
+}
+
+// helloRemoteAddr extracts the address of a client which failed to complete a channel hello, from messages like
+// error receiving hello from [tls:10.0.0.9:44321]
+var helloRemoteAddr = FieldCaptures("msg", `hello from \[\w+:(?P<remoteAddr>[^\]]+)\]`)
+
+// errorCircuitId extracts the circuit id from forwarding errors, such as
+// cannot forward payload, no destination for circuit=[xOq3Gr0bK]
+var errorCircuitId = FieldCaptures("error", `circuit=\[(?P<circuitId>[^\]]+)\]`)
+
+// tlsRemoteAddr extracts the address of a client which failed a TLS handshake with the http server, from messages like
+// http: TLS handshake error from 10.0.0.9:44321: EOF
+var tlsRemoteAddr = FieldCaptures("msg", `TLS handshake error from (?P<remoteAddr>\S+):`)
+
+// linkIdRef extracts the link id from messages which refer to a link as [l/<id>]
+var linkIdRef = FieldCaptures("msg", `\[l/(?P<linkId>[^\]]+)\]`)
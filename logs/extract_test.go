@@ -0,0 +1,52 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"testing"
+)
+
+// TestWhereOnExtractedField checks that --where sees the fields extracted by the filter which matched the entry
+func TestWhereOnExtractedField(t *testing.T) {
+	parser := newTestParser(t, "controller")
+	handler := &sampleHandler{}
+	parser.handler = handler
+
+	var err error
+	if parser.whereMatcher, err = ParseQuery(`remoteAddr == "10.0.0.9:44321"`); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := `{"file":"github.com/openziti/foundation@v0.15.0/channel2/classic_listener.go:120","level":"error","msg":"error receiving hello from [tls:10.0.0.8:51000] (receive error (EOF))","time":"2024-03-01T10:00:00Z"}
+{"file":"github.com/openziti/foundation@v0.15.0/channel2/classic_listener.go:120","level":"error","msg":"error receiving hello from [tls:10.0.0.9:44321] (receive error (EOF))","time":"2024-03-01T10:00:01Z"}
+{"file":"github.com/openziti/fabric@v0.22.0/controller/network/network.go:100","level":"info","msg":"not a message any filter knows about","time":"2024-03-01T10:00:02Z"}
+`
+	if err = parser.scanLines(lines); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(handler.results) != 1 {
+		t.Fatalf("expected 1 entry, got %v", len(handler.results))
+	}
+	result := handler.results[0]
+	if ids := result.getIds(); len(ids) != 1 || ids[0] != "CHANNEL_TLS_EOF" {
+		t.Errorf("expected CHANNEL_TLS_EOF, got %v", ids)
+	}
+	if addr := result.fields["remoteAddr"]; addr != "10.0.0.9:44321" {
+		t.Errorf("expected remoteAddr 10.0.0.9:44321, got '%v'", addr)
+	}
+}
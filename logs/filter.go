@@ -18,13 +18,17 @@ package logs
 
 import (
 	"fmt"
-	"github.com/openziti/foundation/v2/stringz"
 )
 
 type LogFilterHandler struct {
 	unmatched    int
 	maxUnmatched int
-	include      []string
+	selector     *filterSelector
+	hasSelection bool
+	// printAll is set when a field query is the only selection, in which case every entry it matched is output
+	printAll    bool
+	minSeverity *Severity
+	color       bool
 }
 
 func (self *LogFilterHandler) HandleNewLine(ctx *JsonParseContext) error {
@@ -34,13 +38,48 @@ func (self *LogFilterHandler) HandleNewLine(ctx *JsonParseContext) error {
 func (self *LogFilterHandler) HandleEnd(*JsonParseContext) {}
 
 func (self *LogFilterHandler) HandleMatch(ctx *JsonParseContext, logFilter LogFilter) error {
-	if stringz.Contains(self.include, logFilter.Id()) {
-		fmt.Println(ctx.line)
+	if self.isIncluded(logFilter) {
+		fmt.Println(logFilter.Severity().colorize(ctx.line, self.color))
 	}
 	return nil
 }
 
+// HandleMatches outputs the entry once if any of the matching filters are included, colored by the most severe
+func (self *LogFilterHandler) HandleMatches(ctx *JsonParseContext, logFilters []LogFilter) error {
+	included := false
+	severity := SeverityInfo
+	for _, logFilter := range logFilters {
+		if self.isIncluded(logFilter) {
+			included = true
+			severity = max(severity, logFilter.Severity())
+		}
+	}
+	if included {
+		fmt.Println(severity.colorize(ctx.line, self.color))
+	}
+	return nil
+}
+
+// isIncluded returns true if the filter was selected and meets the minimum severity. Matches are only output if
+// some selection was made on the command line
+func (self *LogFilterHandler) isIncluded(logFilter LogFilter) bool {
+	if self.printAll {
+		return true
+	}
+	if !self.hasSelection && self.minSeverity == nil {
+		return false
+	}
+	if self.minSeverity != nil && logFilter.Severity() < *self.minSeverity {
+		return false
+	}
+	return self.selector.isSelected(logFilter)
+}
+
 func (self *LogFilterHandler) HandleUnmatched(ctx *JsonParseContext) error {
+	if self.printAll {
+		fmt.Println(ctx.line)
+		return nil
+	}
 	self.unmatched++
 	if self.unmatched <= self.maxUnmatched {
 		fmt.Printf("WARN: unmatched line: %v\n\n", ctx.line)
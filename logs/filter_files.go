@@ -0,0 +1,277 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// filterFileDef is the top level of a filter definition file. Since JSON is a subset of YAML, files may be in either
+// format. The json tags are used when outputting definitions, such as those suggested for unmatched entries
+type filterFileDef struct {
+	Filters []*filterDef `yaml:"filters" json:"filters"`
+}
+
+type filterDef struct {
+	Id         string        `yaml:"id" json:"id"`
+	Desc       string        `yaml:"desc,omitempty" json:"desc,omitempty"`
+	MinVersion string        `yaml:"minVersion,omitempty" json:"minVersion,omitempty"`
+	MaxVersion string        `yaml:"maxVersion,omitempty" json:"maxVersion,omitempty"`
+	Severity   string        `yaml:"severity,omitempty" json:"severity,omitempty"`
+	Shared     bool          `yaml:"shared,omitempty" json:"shared,omitempty"`
+	Match      *matcherDef   `yaml:"match" json:"match"`
+	Extract    []*extractDef `yaml:"extract,omitempty" json:"extract,omitempty"`
+}
+
+// extractDef sets a virtual field for each named capture in the regex, when the filter matches an entry
+type extractDef struct {
+	Field string `yaml:"field" json:"field"`
+	Regex string `yaml:"regex" json:"regex"`
+}
+
+// matcherDef mirrors the matcher functions. A matcher is either a list of and/or matchers, or a field with exactly one
+// of the comparisons
+type matcherDef struct {
+	And        []*matcherDef `yaml:"and,omitempty" json:"and,omitempty"`
+	Or         []*matcherDef `yaml:"or,omitempty" json:"or,omitempty"`
+	Field      string        `yaml:"field,omitempty" json:"field,omitempty"`
+	Equals     *string       `yaml:"equals,omitempty" json:"equals,omitempty"`
+	Contains   *string       `yaml:"contains,omitempty" json:"contains,omitempty"`
+	StartsWith *string       `yaml:"startsWith,omitempty" json:"startsWith,omitempty"`
+	Matches    *string       `yaml:"matches,omitempty" json:"matches,omitempty"`
+}
+
+func (self *matcherDef) toMatcher() (LogMatcher, error) {
+	set := 0
+	for _, isSet := range []bool{self.And != nil, self.Or != nil, self.Field != ""} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, errors.New("matcher must have exactly one of and, or, field")
+	}
+
+	if self.And != nil || self.Or != nil {
+		children := self.And
+		if self.Or != nil {
+			children = self.Or
+		}
+		var matchers []LogMatcher
+		for idx, child := range children {
+			if child == nil {
+				return nil, errors.Errorf("matcher at index %v is empty", idx)
+			}
+			matcher, err := child.toMatcher()
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid matcher at index %v", idx)
+			}
+			matchers = append(matchers, matcher)
+		}
+		if len(matchers) == 0 {
+			return nil, errors.New("and/or matcher must have at least one child matcher")
+		}
+		if self.And != nil {
+			return AndMatchers(matchers...), nil
+		}
+		return OrMatchers(matchers...), nil
+	}
+
+	var result LogMatcher
+	set = 0
+	if self.Equals != nil {
+		result = FieldEquals(self.Field, *self.Equals)
+		set++
+	}
+	if self.Contains != nil {
+		result = FieldContains(self.Field, *self.Contains)
+		set++
+	}
+	if self.StartsWith != nil {
+		result = FieldStartsWith(self.Field, *self.StartsWith)
+		set++
+	}
+	if self.Matches != nil {
+		regex, err := regexp.Compile(*self.Matches)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid regex for field %v", self.Field)
+		}
+		result = &EntryFieldMatchesMatcher{field: self.Field, regex: regex}
+		set++
+	}
+	if set != 1 {
+		return nil, errors.Errorf("matcher for field %v must have exactly one of equals, contains, startsWith, matches", self.Field)
+	}
+	return result, nil
+}
+
+// loadFilterFile reads the filter definitions from the given YAML or JSON file
+func loadFilterFile(path string) ([]LogFilter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fileDef := &filterFileDef{}
+	if err = yaml.Unmarshal(data, fileDef); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse filter file %v", path)
+	}
+
+	var result []LogFilter
+	for idx, def := range fileDef.Filters {
+		if def == nil || def.Id == "" {
+			return nil, errors.Errorf("filter at index %v in %v has no id", idx, path)
+		}
+		if def.Match == nil {
+			return nil, errors.Errorf("filter %v in %v has no match", def.Id, path)
+		}
+		matcher, err := def.Match.toMatcher()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid filter %v in %v", def.Id, path)
+		}
+		severity := SeverityInfo
+		if def.Severity != "" {
+			if severity, err = ParseSeverity(def.Severity); err != nil {
+				return nil, errors.Wrapf(err, "invalid filter %v in %v", def.Id, path)
+			}
+		}
+		var extract []*FieldExtraction
+		for extractIdx, extractDef := range def.Extract {
+			if extractDef == nil || extractDef.Field == "" {
+				return nil, errors.Errorf("extract at index %v of filter %v in %v has no field", extractIdx, def.Id, path)
+			}
+			extraction := FieldCaptures(extractDef.Field, extractDef.Regex)
+			if err = extraction.validate(); err != nil {
+				return nil, errors.Wrapf(err, "invalid filter %v in %v", def.Id, path)
+			}
+			extract = append(extract, extraction)
+		}
+		result = append(result, &filter{
+			LogMatcher: matcher,
+			id:         def.Id,
+			desc:       def.Desc,
+			minVersion: def.MinVersion,
+			maxVersion: def.MaxVersion,
+			severity:   severity,
+			shared:     def.Shared,
+			extract:    extract,
+		})
+	}
+	return result, nil
+}
+
+// getDefaultFilterFiles returns the filter files found in the default search path for the given component, which is
+// <user config dir>/ziti-ops/filters/<component>
+func getDefaultFilterFiles(component string) []string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+
+	dir := filepath.Join(configDir, "ziti-ops", "filters", component)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var result []string
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yml", ".yaml", ".json":
+			result = append(result, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// loadFilterFiles loads the filters from the default search path, followed by those given on the command line, and
+// merges them with the built-in filters. A loaded filter with the same id as a built-in filter, or as a filter from an
+// earlier file, replaces it. Other loaded filters are checked before the built-in filters, so they can match entries
+// the built-in filters would otherwise claim
+func (self *JsonLogsParser) loadFilterFiles() error {
+	if self.filtersLoaded {
+		return nil
+	}
+	self.filtersLoaded = true
+
+	var sources [][]LogFilter
+	for _, path := range append(getDefaultFilterFiles(self.component), self.filterFiles...) {
+		filters, err := loadFilterFile(path)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, filters)
+	}
+
+	self.filters = mergeFilters(self.filters, sources)
+	return nil
+}
+
+// mergeFilters merges the filters loaded from each source with the built-in filters. Later sources win, so a filter
+// takes the place of any filter with the same id from the built-in filters or from an earlier source. The same id
+// loaded twice from one source isn't an override, so that validate reports the duplicate
+func mergeFilters(builtIn []LogFilter, sources [][]LogFilter) []LogFilter {
+	builtInIds := map[string]struct{}{}
+	for _, f := range builtIn {
+		builtInIds[f.Id()] = struct{}{}
+	}
+
+	overrides := map[string]LogFilter{}
+	loadedIndexes := map[string]int{}
+	var loaded []LogFilter
+	for _, source := range sources {
+		sourceIds := map[string]struct{}{}
+		for _, f := range source {
+			id := f.Id()
+			if _, found := sourceIds[id]; found {
+				loaded = append(loaded, f)
+				continue
+			}
+			sourceIds[id] = struct{}{}
+
+			if _, found := builtInIds[id]; found {
+				overrides[id] = f
+			} else if idx, found := loadedIndexes[id]; found {
+				loaded[idx] = f
+			} else {
+				loadedIndexes[id] = len(loaded)
+				loaded = append(loaded, f)
+			}
+		}
+	}
+
+	result := loaded
+	for _, f := range builtIn {
+		if override, found := overrides[f.Id()]; found {
+			result = append(result, override)
+		} else {
+			result = append(result, f)
+		}
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const defaultPathFilters = `filters:
+  - id: CTRL_CH_RECONNECT_OK
+    desc: from the default path
+    match: {field: msg, equals: reconnected}
+  - id: MY_FILTER
+    desc: from the default path
+    match: {field: msg, equals: mine}
+`
+
+const explicitFilters = `filters:
+  - id: CTRL_CH_RECONNECT_OK
+    desc: from the command line
+    match: {field: msg, equals: reconnected}
+  - id: MY_FILTER
+    desc: from the command line
+    match: {field: msg, equals: mine}
+`
+
+// TestFilterFilePrecedence checks that filters from --filters-file replace those with the same id from the default
+// search path, for both built-in and new ids, rather than being reported as duplicates
+func TestFilterFilePrecedence(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defaultDir := filepath.Join(configDir, "ziti-ops", "filters", "router")
+	if err = os.MkdirAll(defaultDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(defaultDir, "default.yml"), []byte(defaultPathFilters), 0644); err != nil {
+		t.Fatal(err)
+	}
+	explicitFile := filepath.Join(home, "explicit.yml")
+	if err = os.WriteFile(explicitFile, []byte(explicitFilters), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parser, err := newComponentParser("router")
+	if err != nil {
+		t.Fatal(err)
+	}
+	builtInCount := len(parser.filters)
+	parser.filterFiles = []string{explicitFile}
+	if err = parser.validateFilters(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(parser.filters) != builtInCount+1 {
+		t.Errorf("expected %v filters, got %v", builtInCount+1, len(parser.filters))
+	}
+	for _, logFilter := range parser.filters {
+		if id := logFilter.Id(); id == "CTRL_CH_RECONNECT_OK" || id == "MY_FILTER" {
+			if desc := logFilter.Desc(); desc != "from the command line" {
+				t.Errorf("expected %v from the command line, got %v", id, desc)
+			}
+		}
+	}
+}
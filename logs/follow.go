@@ -0,0 +1,188 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const followPollInterval = 250 * time.Millisecond
+
+// IdleHandler may be implemented by an EntryHandler which wants to be notified when following logs and no new input
+// is available, for example to output data which is time based
+type IdleHandler interface {
+	HandleIdle(now time.Time) error
+}
+
+type followConfig struct {
+	done   <-chan struct{}
+	onIdle func() error
+}
+
+// followReader reads a file like tail -F. When the end of the file is reached it waits for more data, reopening the
+// file if it's been rotated and starting over if it's been truncated. It returns io.EOF once done is closed
+type followReader struct {
+	path   string
+	file   *os.File
+	offset int64
+	config *followConfig
+}
+
+func newFollowReader(path string, config *followConfig) (*followReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, maxMagicLen)
+	n, _ := file.ReadAt(header, 0)
+	if compression := getCompression(header[:n]); compression != "" {
+		_ = file.Close()
+		return nil, errors.Errorf("unable to follow %v, it is %v compressed", path, compression)
+	}
+
+	return &followReader{
+		path:   path,
+		file:   file,
+		config: config,
+	}, nil
+}
+
+func (self *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := self.file.Read(p)
+		if n > 0 {
+			self.offset += int64(n)
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		reopened, err := self.checkRotated()
+		if err != nil {
+			return 0, err
+		}
+		if reopened {
+			continue
+		}
+
+		if err = self.config.onIdle(); err != nil {
+			return 0, err
+		}
+
+		select {
+		case <-self.config.done:
+			return 0, io.EOF
+		case <-time.After(followPollInterval):
+		}
+	}
+}
+
+// checkRotated returns true if the file was replaced or truncated and reading should start again from the beginning
+func (self *followReader) checkRotated() (bool, error) {
+	pathInfo, err := os.Stat(self.path)
+	if err != nil {
+		// the file may be in the middle of being rotated, so wait for it to reappear
+		return false, nil
+	}
+
+	fileInfo, err := self.file.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	if !os.SameFile(pathInfo, fileInfo) {
+		file, err := os.Open(self.path)
+		if err != nil {
+			return false, nil
+		}
+		_ = self.file.Close()
+		self.file = file
+		self.offset = 0
+		return true, nil
+	}
+
+	if fileInfo.Size() < self.offset {
+		if _, err = self.file.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		self.offset = 0
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (self *followReader) Close() error {
+	return self.file.Close()
+}
+
+// FollowJsonLines works like ScanJsonLines, but once the last input has been read it keeps waiting for new lines
+// until interrupted. onIdle is called whenever no new input is available
+func FollowJsonLines(inputs []string, options *InputOptions, onIdle func(ctx *JsonParseContext) error, callback func(ctx *JsonParseContext) error) error {
+	paths, err := expandInputs(inputs)
+	if err != nil {
+		return err
+	}
+
+	signalCtx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var ctx *JsonParseContext
+	config := &followConfig{
+		done: signalCtx.Done(),
+		onIdle: func() error {
+			return onIdle(ctx)
+		},
+	}
+
+	ctx = newJsonParseContext(orderByFirstTimestamp(paths, options), options)
+	ctx.follow = config
+	return ctx.scan(callback)
+}
+
+func (self *JsonLogsParser) handleIdle(ctx *JsonParseContext) error {
+	// a non-json block such as a panic is only matched once we see the following line, so if nothing else is coming,
+	// match it now
+	if ctx.nonJson.Len() > 0 {
+		if err := self.checkNonJson(ctx); err != nil {
+			return err
+		}
+	}
+
+	if idleHandler, ok := self.handler.(IdleHandler); ok {
+		return idleHandler.HandleIdle(time.Now())
+	}
+	return nil
+}
+
+// scan processes the given inputs with the configured handler, following them if requested
+func (self *JsonLogsParser) scan(inputs []string) error {
+	options := self.getInputOptions()
+	if self.follow {
+		return FollowJsonLines(inputs, options, self.handleIdle, self.processLogEntry)
+	}
+	return ScanJsonLines(inputs, options, self.processLogEntry)
+}
@@ -0,0 +1,349 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	InputFormatAuto            = "auto"
+	InputFormatJson            = "json"
+	InputFormatJournald        = "journald"
+	InputFormatJournaldIso     = "journald-iso"
+	InputFormatJournaldPrecise = "journald-precise"
+	InputFormatJournaldJson    = "journald-json"
+	InputFormatDocker          = "docker"
+	InputFormatCri             = "cri"
+)
+
+// InputOptions control how input lines are unwrapped and how log collector timestamps are interpreted
+type InputOptions struct {
+	// Format should be one of InputFormats. If it's auto, the format is detected separately for each file
+	Format string
+	// Location is used for log collector timestamps which don't include a time zone
+	Location *time.Location
+}
+
+// InputFormats lists the supported input formats, in the order they're shown in help output
+var InputFormats = []string{
+	InputFormatAuto,
+	InputFormatJson,
+	InputFormatJournald,
+	InputFormatJournaldIso,
+	InputFormatJournaldPrecise,
+	InputFormatJournaldJson,
+	InputFormatDocker,
+	InputFormatCri,
+}
+
+// lineUnwrapper strips any wrapping added by the log collector from the current line, leaving the ziti log entry in
+// ctx.line and recording the collector's timestamp and process name where available. It returns false if the line
+// shouldn't be passed on, either because it's a header or because it's only part of a line
+type lineUnwrapper func(ctx *ParseContext) bool
+
+var lineUnwrappers = map[string]lineUnwrapper{
+	InputFormatJson:            unwrapJson,
+	InputFormatJournald:        newJournaldUnwrapper(3, time.Stamp),
+	InputFormatJournaldIso:     newJournaldUnwrapper(1, "2006-01-02T15:04:05-0700", "2006-01-02T15:04:05Z07:00"),
+	InputFormatJournaldPrecise: newJournaldUnwrapper(3, time.StampMicro),
+	InputFormatJournaldJson:    unwrapJournaldJson,
+	InputFormatDocker:          unwrapDocker,
+	InputFormatCri:             unwrapCri,
+}
+
+func validateInputFormat(format string) error {
+	if format == InputFormatAuto {
+		return nil
+	}
+	if _, found := lineUnwrappers[format]; !found {
+		return errors.Errorf("invalid input format '%v'. Valid formats: %v", format, strings.Join(InputFormats, ", "))
+	}
+	return nil
+}
+
+var (
+	criLineRegex             = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\S+ (stdout|stderr) [FP]\S* `)
+	journaldIsoLineRegex     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2}) \S+ [^:]+:`)
+	journaldPreciseLineRegex = regexp.MustCompile(`^[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}\.\d+ \S+ `)
+	journaldLineRegex        = regexp.MustCompile(`^[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2} \S+ `)
+)
+
+// detectInputFormat works out the input format from a single line. It returns an empty string if the line doesn't
+// tell us, such as for blank lines or journald headers
+func detectInputFormat(line string) string {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "-- ") {
+		return ""
+	}
+
+	if trimmed[0] == '{' {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(trimmed), &fields); err == nil {
+			if _, found := fields["__REALTIME_TIMESTAMP"]; found {
+				return InputFormatJournaldJson
+			}
+			_, hasLog := fields["log"]
+			_, hasStream := fields["stream"]
+			if hasLog && hasStream {
+				return InputFormatDocker
+			}
+		}
+		return InputFormatJson
+	}
+
+	switch {
+	case criLineRegex.MatchString(line):
+		return InputFormatCri
+	case journaldIsoLineRegex.MatchString(line):
+		return InputFormatJournaldIso
+	case journaldPreciseLineRegex.MatchString(line):
+		return InputFormatJournaldPrecise
+	case journaldLineRegex.MatchString(line):
+		return InputFormatJournald
+	}
+
+	// most likely the start of a panic or other non-json output in a raw log file
+	return InputFormatJson
+}
+
+// unwrapLine applies the input format to the current line, detecting the format from the content if necessary
+func (self *ParseContext) unwrapLine() bool {
+	self.outerTime = time.Time{}
+	self.process = ""
+
+	if self.format == InputFormatAuto {
+		format := detectInputFormat(self.line)
+		if format == "" {
+			// only blank lines and journald headers are undetected. The journald unwrapper will drop the headers and
+			// pass the rest through unchanged
+			return lineUnwrappers[InputFormatJournald](self)
+		}
+		self.format = format
+	}
+
+	result := lineUnwrappers[self.format](self)
+	if !self.outerTime.IsZero() {
+		if self.outerTime.Year() == 0 {
+			self.outerTime = self.resolveYear(self.outerTime)
+		}
+		self.lastTime = self.outerTime
+	}
+	return result
+}
+
+// resolveYear fills in the year for timestamps, such as journald's short format, which don't include one
+func (self *ParseContext) resolveYear(t time.Time) time.Time {
+	if !self.lastTime.IsZero() {
+		// use the year which puts the timestamp closest to the last one we saw, which takes care of new year rollover
+		year := self.lastTime.Year()
+		result := withYear(t, year)
+		for _, candidate := range []time.Time{withYear(t, year-1), withYear(t, year+1)} {
+			if absDuration(candidate.Sub(self.lastTime)) < absDuration(result.Sub(self.lastTime)) {
+				result = candidate
+			}
+		}
+		return result
+	}
+
+	// with nothing to compare to, use the latest year which doesn't put the timestamp after the file was last
+	// modified. Allow some slack in case the time zone is off
+	result := withYear(t, self.modTime.Year())
+	if result.After(self.modTime.Add(24 * time.Hour)) {
+		result = withYear(t, self.modTime.Year()-1)
+	}
+	return result
+}
+
+func withYear(t time.Time, year int) time.Time {
+	return time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// updateLastTime records the timestamp of the current json entry, which is used to work out the year of later
+// timestamps which don't include one
+func (self *JsonParseContext) updateLastTime() {
+	if self.entry == nil {
+		return
+	}
+	if t, err := time.Parse(time.RFC3339, self.GetString("time")); err == nil {
+		self.lastTime = t
+	}
+}
+
+func unwrapJson(*ParseContext) bool {
+	return true
+}
+
+// newJournaldUnwrapper returns an unwrapper for the journalctl short output modes. Lines look like
+// '<timestamp> <host> <process>[<pid>]: <message>', where the timestamp is made up of timestampFields space separated
+// fields
+func newJournaldUnwrapper(timestampFields int, layouts ...string) lineUnwrapper {
+	return func(ctx *ParseContext) bool {
+		if strings.HasPrefix(ctx.line, "-- ") {
+			if strings.HasPrefix(ctx.line, "-- Logs begin at") || strings.HasPrefix(ctx.line, "-- Journal begins at") {
+				return false
+			}
+			// reboot markers and the like
+			ctx.process = "journald"
+			return true
+		}
+
+		fields, rest, ok := cutFields(ctx.line, timestampFields+1)
+		if !ok {
+			return true
+		}
+
+		timestamp := strings.Join(fields[:timestampFields], " ")
+		for _, layout := range layouts {
+			if t, err := time.ParseInLocation(layout, timestamp, ctx.options.Location); err == nil {
+				ctx.outerTime = t
+				break
+			}
+		}
+
+		ctx.process, ctx.line = splitFirst(rest, ':')
+		ctx.process, _ = splitFirst(ctx.process, '[')
+		return true
+	}
+}
+
+type journaldJsonEntry struct {
+	RealtimeTimestamp string          `json:"__REALTIME_TIMESTAMP"`
+	Message           json.RawMessage `json:"MESSAGE"`
+	SyslogIdentifier  string          `json:"SYSLOG_IDENTIFIER"`
+	Comm              string          `json:"_COMM"`
+}
+
+// unwrapJournaldJson handles journalctl -o json output, where each line is a json object with the original line in
+// the MESSAGE field
+func unwrapJournaldJson(ctx *ParseContext) bool {
+	entry := &journaldJsonEntry{}
+	if err := json.Unmarshal([]byte(ctx.line), entry); err != nil {
+		return true
+	}
+
+	// journald outputs messages which aren't valid utf-8 as an array of bytes
+	var message string
+	if err := json.Unmarshal(entry.Message, &message); err != nil {
+		var raw []byte
+		var values []int
+		if err = json.Unmarshal(entry.Message, &values); err != nil {
+			return true
+		}
+		for _, v := range values {
+			raw = append(raw, byte(v))
+		}
+		message = string(raw)
+	}
+
+	if micros, err := strconv.ParseInt(entry.RealtimeTimestamp, 10, 64); err == nil {
+		ctx.outerTime = time.UnixMicro(micros)
+	}
+
+	ctx.process = entry.SyslogIdentifier
+	if ctx.process == "" {
+		ctx.process = entry.Comm
+	}
+	ctx.line = message
+	return true
+}
+
+type dockerEntry struct {
+	Log    string `json:"log"`
+	Stream string `json:"stream"`
+	Time   string `json:"time"`
+}
+
+// unwrapDocker handles the docker json-file log driver format. Docker splits long lines, in which case only the last
+// part ends with a newline
+func unwrapDocker(ctx *ParseContext) bool {
+	entry := &dockerEntry{}
+	if err := json.Unmarshal([]byte(ctx.line), entry); err != nil {
+		return true
+	}
+
+	if !strings.HasSuffix(entry.Log, "\n") {
+		ctx.partial.WriteString(entry.Log)
+		return false
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, entry.Time); err == nil {
+		ctx.outerTime = t
+	}
+	ctx.line = ctx.takePartial(strings.TrimSuffix(entry.Log, "\n"))
+	return true
+}
+
+// unwrapCri handles the kubernetes CRI log format: '<timestamp> <stream> <tag> <message>', where a tag of P marks
+// a partial line
+func unwrapCri(ctx *ParseContext) bool {
+	fields, rest, ok := cutFields(ctx.line, 3)
+	if !ok {
+		return true
+	}
+
+	if strings.HasPrefix(fields[2], "P") {
+		ctx.partial.WriteString(rest)
+		return false
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, fields[0]); err == nil {
+		ctx.outerTime = t
+	}
+	ctx.line = ctx.takePartial(rest)
+	return true
+}
+
+// takePartial returns any previously seen parts of the current line joined with the final part
+func (self *ParseContext) takePartial(last string) string {
+	if self.partial.Len() == 0 {
+		return last
+	}
+	self.partial.WriteString(last)
+	result := self.partial.String()
+	self.partial.Reset()
+	return result
+}
+
+// cutFields returns the first n space separated fields of s and the remainder of s following the separator after the
+// last field
+func cutFields(s string, n int) ([]string, string, bool) {
+	var fields []string
+	for len(fields) < n {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			return nil, "", false
+		}
+		var field string
+		field, s, _ = strings.Cut(s, " ")
+		fields = append(fields, field)
+	}
+	return fields, s, true
+}
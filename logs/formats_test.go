@@ -0,0 +1,218 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectInputFormat(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected string
+	}{
+		{``, ""},
+		{`   `, ""},
+		{`-- Logs begin at Tue 2024-03-01 10:00:00 UTC. --`, ""},
+		{`{"file":"router/xgress/xgress.go:357","level":"error","msg":"read failed"}`, InputFormatJson},
+		{`{"log":"{\"msg\":\"read failed\"}\n","stream":"stderr","time":"2024-03-01T10:00:00.123456789Z"}`, InputFormatDocker},
+		{`{"log":"no stream"}`, InputFormatJson},
+		{`{"__REALTIME_TIMESTAMP":"1709287200000000","MESSAGE":"{}"}`, InputFormatJournaldJson},
+		{`{not json`, InputFormatJson},
+		{`2024-03-01T10:00:00.123456789Z stderr F {"msg":"read failed"}`, InputFormatCri},
+		{`2024-03-01T10:00:00.123456789+01:00 stdout P {"msg":"read`, InputFormatCri},
+		{`2024-03-01T10:00:00+0000 router1 ziti-router[100]: {"msg":"read failed"}`, InputFormatJournaldIso},
+		{`Mar 01 10:00:00.123456 router1 ziti-router[100]: {"msg":"read failed"}`, InputFormatJournaldPrecise},
+		{`Mar 01 10:00:00 router1 ziti-router[100]: {"msg":"read failed"}`, InputFormatJournald},
+		{`Mar  1 10:00:00 router1 ziti-router[100]: {"msg":"read failed"}`, InputFormatJournald},
+		{`panic: runtime error: invalid memory address or nil pointer dereference`, InputFormatJson},
+		{`goroutine 1 [running]:`, InputFormatJson},
+	}
+
+	for _, test := range tests {
+		if actual := detectInputFormat(test.line); actual != test.expected {
+			t.Errorf("%v: expected '%v', got '%v'", test.line, test.expected, actual)
+		}
+	}
+}
+
+// unwrappedLine is a line passed on by an unwrapper, along with the log collector fields it recorded
+type unwrappedLine struct {
+	line      string
+	process   string
+	outerTime time.Time
+}
+
+// unwrapLines runs the lines through unwrapLine using the given format, returning the lines which are passed on
+func unwrapLines(format string, lines string) []unwrappedLine {
+	ctx := newJsonParseContext(nil, &InputOptions{Format: format, Location: time.UTC})
+	ctx.format = format
+	ctx.modTime = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	var result []unwrappedLine
+	for _, line := range strings.Split(strings.TrimSuffix(lines, "\n"), "\n") {
+		ctx.line = line
+		if ctx.unwrapLine() {
+			result = append(result, unwrappedLine{line: ctx.line, process: ctx.process, outerTime: ctx.outerTime})
+		}
+	}
+	return result
+}
+
+func TestLineUnwrappers(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		format   string
+		lines    string
+		expected []unwrappedLine
+	}{
+		{
+			name:     "json",
+			format:   InputFormatJson,
+			lines:    `{"msg":"read failed"}`,
+			expected: []unwrappedLine{{line: `{"msg":"read failed"}`}},
+		},
+		{
+			name:   "journald",
+			format: InputFormatJournald,
+			lines: `-- Logs begin at Fri 2024-03-01 09:00:00 UTC, end at Fri 2024-03-01 11:00:00 UTC. --
+Mar 01 10:00:00 router1 ziti-router[100]: {"msg":"read failed"}
+-- Reboot --
+`,
+			expected: []unwrappedLine{
+				{line: ` {"msg":"read failed"}`, process: "ziti-router", outerTime: ts},
+				{line: `-- Reboot --`, process: "journald"},
+			},
+		},
+		{
+			name:   "journald iso",
+			format: InputFormatJournaldIso,
+			lines:  `2024-03-01T11:00:00+0100 router1 ziti-router[100]: {"msg":"read failed"}`,
+			expected: []unwrappedLine{
+				{line: ` {"msg":"read failed"}`, process: "ziti-router", outerTime: ts},
+			},
+		},
+		{
+			name:   "journald precise",
+			format: InputFormatJournaldPrecise,
+			lines:  `Mar 01 10:00:00.250000 router1 ziti-router[100]: {"msg":"read failed"}`,
+			expected: []unwrappedLine{
+				{line: ` {"msg":"read failed"}`, process: "ziti-router", outerTime: ts.Add(250 * time.Millisecond)},
+			},
+		},
+		{
+			name:   "journald json",
+			format: InputFormatJournaldJson,
+			lines: `{"__REALTIME_TIMESTAMP":"1709287200000000","MESSAGE":"{\"msg\":\"read failed\"}","SYSLOG_IDENTIFIER":"ziti-router","_COMM":"ziti"}
+{"__REALTIME_TIMESTAMP":"1709287200000000","MESSAGE":[104,105],"_COMM":"ziti"}
+`,
+			expected: []unwrappedLine{
+				{line: `{"msg":"read failed"}`, process: "ziti-router", outerTime: ts},
+				{line: `hi`, process: "ziti", outerTime: ts},
+			},
+		},
+		{
+			name:   "docker",
+			format: InputFormatDocker,
+			lines:  `{"log":"{\"msg\":\"read failed\"}\n","stream":"stderr","time":"2024-03-01T10:00:00Z"}`,
+			expected: []unwrappedLine{
+				{line: `{"msg":"read failed"}`, outerTime: ts},
+			},
+		},
+		{
+			name:   "docker split line",
+			format: InputFormatDocker,
+			lines: `{"log":"{\"msg\":","stream":"stderr","time":"2024-03-01T09:59:59Z"}
+{"log":"\"read ","stream":"stderr","time":"2024-03-01T09:59:59Z"}
+{"log":"failed\"}\n","stream":"stderr","time":"2024-03-01T10:00:00Z"}
+{"log":"{\"msg\":\"next\"}\n","stream":"stderr","time":"2024-03-01T10:00:00Z"}
+`,
+			expected: []unwrappedLine{
+				{line: `{"msg":"read failed"}`, outerTime: ts},
+				{line: `{"msg":"next"}`, outerTime: ts},
+			},
+		},
+		{
+			name:   "cri",
+			format: InputFormatCri,
+			lines:  `2024-03-01T10:00:00.000000000Z stderr F {"msg":"read failed"}`,
+			expected: []unwrappedLine{
+				{line: `{"msg":"read failed"}`, outerTime: ts},
+			},
+		},
+		{
+			name:   "cri partial lines",
+			format: InputFormatCri,
+			lines: `2024-03-01T09:59:59.000000000Z stderr P {"msg":
+2024-03-01T09:59:59.500000000Z stderr P "read fa
+2024-03-01T10:00:00.000000000Z stderr F iled"}
+2024-03-01T10:00:00.000000000Z stderr F {"msg":"next"}
+`,
+			expected: []unwrappedLine{
+				{line: `{"msg":"read failed"}`, outerTime: ts},
+				{line: `{"msg":"next"}`, outerTime: ts},
+			},
+		},
+		{
+			name:   "cri empty final part",
+			format: InputFormatCri,
+			lines: `2024-03-01T09:59:59.000000000Z stdout P {"msg":"read failed"}
+2024-03-01T10:00:00.000000000Z stdout F
+`,
+			expected: []unwrappedLine{
+				{line: `{"msg":"read failed"}`, outerTime: ts},
+			},
+		},
+		{
+			name:   "auto",
+			format: InputFormatAuto,
+			lines: `
+2024-03-01T10:00:00.000000000Z stderr F {"msg":"read failed"}
+2024-03-01T10:00:00.000000000Z stderr F {"msg":"next"}
+`,
+			expected: []unwrappedLine{
+				{line: ``},
+				{line: `{"msg":"read failed"}`, outerTime: ts},
+				{line: `{"msg":"next"}`, outerTime: ts},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := unwrapLines(test.format, test.lines)
+			if len(actual) != len(test.expected) {
+				t.Fatalf("expected %v lines, got %v: %v", len(test.expected), len(actual), actual)
+			}
+			for idx, expected := range test.expected {
+				if actual[idx].line != expected.line {
+					t.Errorf("line %v: expected '%v', got '%v'", idx, expected.line, actual[idx].line)
+				}
+				if actual[idx].process != expected.process {
+					t.Errorf("line %v: expected process '%v', got '%v'", idx, expected.process, actual[idx].process)
+				}
+				if !actual[idx].outerTime.Equal(expected.outerTime) {
+					t.Errorf("line %v: expected time %v, got %v", idx, expected.outerTime, actual[idx].outerTime)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	"github.com/ulikunitz/xz"
+)
+
+// StdinInput is the input name used to read logs from stdin
+const StdinInput = "-"
+
+// maxLinesForFirstTimestamp bounds how far into a file we'll look for a timestamp when ordering inputs
+const maxLinesForFirstTimestamp = 1000
+
+var errStopScan = errors.New("stop scan")
+
+// expandInputs turns the given arguments into a list of files. Arguments may be files, directories, whose regular
+// files are included, or glob patterns
+func expandInputs(inputs []string) ([]string, error) {
+	if len(inputs) == 0 {
+		return nil, errors.New("no log files specified")
+	}
+
+	var result []string
+	seen := map[string]struct{}{}
+	add := func(path string) {
+		if _, found := seen[path]; !found {
+			seen[path] = struct{}{}
+			result = append(result, path)
+		}
+	}
+
+	for _, input := range inputs {
+		if input == StdinInput {
+			add(input)
+			continue
+		}
+
+		var candidates []string
+		if strings.ContainsAny(input, "*?[") {
+			matches, err := filepath.Glob(input)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid glob pattern '%v'", input)
+			}
+			if len(matches) == 0 {
+				return nil, errors.Errorf("no files match '%v'", input)
+			}
+			candidates = matches
+		} else {
+			candidates = []string{input}
+		}
+
+		for _, candidate := range candidates {
+			info, err := os.Stat(candidate)
+			if err != nil {
+				return nil, err
+			}
+
+			if !info.IsDir() {
+				add(candidate)
+				continue
+			}
+
+			entries, err := os.ReadDir(candidate)
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range entries {
+				if entry.Type().IsRegular() {
+					add(filepath.Join(candidate, entry.Name()))
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// orderByFirstTimestamp sorts the given files by the first json entry timestamp found in each. Files where no
+// timestamp can be found, including stdin, which can only be read once, keep their relative order and are placed after
+// the files which have one
+func orderByFirstTimestamp(paths []string, options *InputOptions) []string {
+	if len(paths) < 2 {
+		return paths
+	}
+
+	firstTimes := map[string]time.Time{}
+	for _, path := range paths {
+		if path == StdinInput {
+			continue
+		}
+		if t, found := getFirstTimestamp(path, options); found {
+			firstTimes[path] = t
+		}
+	}
+
+	result := append([]string(nil), paths...)
+	sort.SliceStable(result, func(i, j int) bool {
+		ti, iFound := firstTimes[result[i]]
+		tj, jFound := firstTimes[result[j]]
+		if iFound && jFound {
+			return ti.Before(tj)
+		}
+		return iFound && !jFound
+	})
+	return result
+}
+
+func getFirstTimestamp(path string, options *InputOptions) (time.Time, bool) {
+	var result time.Time
+	found := false
+	err := scanJsonLines([]string{path}, options, func(ctx *JsonParseContext) error {
+		if ctx.eof || ctx.lineNumber > maxLinesForFirstTimestamp {
+			return errStopScan
+		}
+		if ctx.entry != nil && ctx.GetString("time") != "" {
+			if t, err := ctx.GetTime(); err == nil {
+				result = t
+				found = true
+				return errStopScan
+			}
+		}
+		return nil
+	})
+	if err != nil && errors.Cause(err) != errStopScan {
+		return time.Time{}, false
+	}
+	return result, found
+}
+
+// getModTime returns the last modified time of the given path, or the current time for stdin or if it can't be found
+func getModTime(path string) time.Time {
+	if path != StdinInput {
+		if info, err := os.Stat(path); err == nil {
+			return info.ModTime()
+		}
+	}
+	return time.Now()
+}
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+const maxMagicLen = 6
+
+// getCompression returns the compression format identified by the given file header, or an empty string if the
+// content isn't compressed
+func getCompression(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return "gzip"
+	case bytes.HasPrefix(header, bzip2Magic):
+		return "bzip2"
+	case bytes.HasPrefix(header, xzMagic):
+		return "xz"
+	case bytes.HasPrefix(header, zstdMagic):
+		return "zstd"
+	}
+	return ""
+}
+
+// openInput opens the given path for reading, transparently decompressing gzip, bzip2, xz and zstd content. The
+// compression format is detected from the content, not the file extension. A path of '-' reads from stdin
+func openInput(path string) (io.ReadCloser, error) {
+	var file *os.File
+	if path == StdinInput {
+		file = os.Stdin
+	} else {
+		var err error
+		if file, err = os.Open(path); err != nil {
+			return nil, err
+		}
+	}
+
+	reader := bufio.NewReader(file)
+	header, _ := reader.Peek(maxMagicLen)
+
+	result := &inputReader{file: file}
+	var err error
+	switch getCompression(header) {
+	case "gzip":
+		result.Reader, err = gzip.NewReader(reader)
+	case "bzip2":
+		result.Reader = bzip2.NewReader(reader)
+	case "xz":
+		result.Reader, err = xz.NewReader(reader)
+	case "zstd":
+		var decoder *zstd.Decoder
+		if decoder, err = zstd.NewReader(reader); err == nil {
+			result.Reader = decoder
+			result.closer = decoder.IOReadCloser()
+		}
+	default:
+		result.Reader = reader
+	}
+
+	if err != nil {
+		_ = result.Close()
+		return nil, errors.Wrapf(err, "unable to read compressed input %v", path)
+	}
+	return result, nil
+}
+
+type inputReader struct {
+	io.Reader
+	file   *os.File
+	closer io.Closer
+}
+
+func (self *inputReader) Close() error {
+	if self.closer != nil {
+		_ = self.closer.Close()
+	}
+	if self.file == os.Stdin {
+		return nil
+	}
+	return self.file.Close()
+}
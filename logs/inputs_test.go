@@ -0,0 +1,190 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+func writeTestFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testEntry(ts string) []byte {
+	return []byte(`{"level":"info","msg":"started","time":"` + ts + `"}` + "\n")
+}
+
+// TestExpandAndOrderInputs checks that directories and globs are expanded to their regular files without duplicates,
+// and that the files are then ordered by their first timestamp, with files that have none last
+func TestExpandAndOrderInputs(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "logs", "b.log"), testEntry("2024-03-01T10:00:02Z"))
+	writeTestFile(t, filepath.Join(dir, "logs", "a.log"), testEntry("2024-03-01T10:00:01Z"))
+	writeTestFile(t, filepath.Join(dir, "logs", "sub", "ignored.log"), testEntry("2024-03-01T09:00:00Z"))
+	writeTestFile(t, filepath.Join(dir, "other", "c.log"), testEntry("2024-03-01T10:00:00Z"))
+	writeTestFile(t, filepath.Join(dir, "other", "c.txt"), testEntry("2024-03-01T08:00:00Z"))
+	writeTestFile(t, filepath.Join(dir, "other", "d.log"), []byte("panic: no timestamps here\n"))
+
+	inputs := []string{
+		filepath.Join(dir, "other", "d.log"),
+		filepath.Join(dir, "logs"),
+		filepath.Join(dir, "other", "*.log"),
+		filepath.Join(dir, "logs", "a.log"),
+	}
+
+	paths, err := expandInputs(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, path := range paths {
+		rel, _ := filepath.Rel(dir, path)
+		names = append(names, filepath.ToSlash(rel))
+	}
+	expected := "other/d.log logs/a.log logs/b.log other/c.log"
+	if actual := strings.Join(names, " "); actual != expected {
+		t.Errorf("expected expanded inputs %v, got %v", expected, actual)
+	}
+
+	names = nil
+	for _, path := range orderByFirstTimestamp(paths, &InputOptions{Format: InputFormatAuto, Location: time.UTC}) {
+		rel, _ := filepath.Rel(dir, path)
+		names = append(names, filepath.ToSlash(rel))
+	}
+	expected = "other/c.log logs/a.log logs/b.log other/d.log"
+	if actual := strings.Join(names, " "); actual != expected {
+		t.Errorf("expected ordered inputs %v, got %v", expected, actual)
+	}
+}
+
+func TestExpandInputsErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := expandInputs(nil); err == nil {
+		t.Error("expected an error without inputs")
+	}
+	if _, err := expandInputs([]string{filepath.Join(dir, "*.log")}); err == nil {
+		t.Error("expected an error for a glob which matches nothing")
+	}
+	if _, err := expandInputs([]string{filepath.Join(dir, "missing.log")}); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+
+	paths, err := expandInputs([]string{StdinInput, StdinInput})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || paths[0] != StdinInput {
+		t.Errorf("expected stdin once, got %v", paths)
+	}
+}
+
+// compressTestData compresses the data using the given compression format
+func compressTestData(t *testing.T, compression string, data []byte) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	var w io.WriteCloser
+	var err error
+	switch compression {
+	case "gzip":
+		w = gzip.NewWriter(buf)
+	case "xz":
+		w, err = xz.NewWriter(buf)
+	case "zstd":
+		w, err = zstd.NewWriter(buf)
+	default:
+		return data
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestCompressedInputs checks that compressed inputs are detected from their content, regardless of the file name,
+// and read transparently
+func TestCompressedInputs(t *testing.T) {
+	dir := t.TempDir()
+	data := append(testEntry("2024-03-01T10:00:00Z"), testEntry("2024-03-01T10:00:01Z")...)
+
+	for _, compression := range []string{"", "gzip", "xz", "zstd"} {
+		name := compression
+		if name == "" {
+			name = "plain"
+		}
+		t.Run(name, func(t *testing.T) {
+			compressed := compressTestData(t, compression, data)
+			if actual := getCompression(compressed); actual != compression {
+				t.Errorf("expected compression '%v', got '%v'", compression, actual)
+			}
+
+			path := filepath.Join(dir, name+".log")
+			writeTestFile(t, path, compressed)
+
+			input, err := openInput(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = input.Close() }()
+
+			actual, err := io.ReadAll(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(actual, data) {
+				t.Errorf("expected %q, got %q", data, actual)
+			}
+		})
+	}
+
+	if actual := getCompression([]byte("BZh91AY&SY")); actual != "bzip2" {
+		t.Errorf("expected compression 'bzip2', got '%v'", actual)
+	}
+	if actual := getCompression([]byte{0x1f}); actual != "" {
+		t.Errorf("expected no compression for a truncated header, got '%v'", actual)
+	}
+
+	path := filepath.Join(dir, "truncated.log.gz")
+	writeTestFile(t, path, compressTestData(t, "gzip", data)[:8])
+	if input, err := openInput(path); err == nil {
+		_ = input.Close()
+		t.Error("expected an error for a truncated gzip header")
+	}
+}
@@ -0,0 +1,264 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// linkEnd is one router's view of a link
+type linkEnd struct {
+	Node string `json:"node"`
+	*linkRecord
+}
+
+// linkPair is a link seen in the logs of both the dialing and accepting routers
+type linkPair struct {
+	Id       string   `json:"id"`
+	Dialer   *linkEnd `json:"dialer"`
+	Acceptor *linkEnd `json:"acceptor"`
+	Skew     string   `json:"skew,omitempty"`
+	Issues   []string `json:"issues,omitempty"`
+	skew     *time.Duration
+}
+
+// estimateSkew returns how far the acceptor's clock is ahead of the dialer's, if that can be determined. The accept
+// has to happen after the dial starts and before the dialer sees the link established, so only an accept time
+// outside that window shows skew, and then by at least the distance outside it
+func (self *linkPair) estimateSkew() *time.Duration {
+	accepted := self.Acceptor.Established
+	lower, upper := self.Dialer.Dialed, self.Dialer.Established
+	if lower == nil {
+		lower = upper
+	}
+	if upper == nil {
+		upper = lower
+	}
+	if accepted == nil || lower == nil {
+		return nil
+	}
+
+	var result time.Duration
+	switch {
+	case accepted.Before(*lower):
+		result = accepted.Sub(*lower)
+	case accepted.After(*upper):
+		result = accepted.Sub(*upper)
+	}
+	return &result
+}
+
+func (self *linkPair) check(maxSkew time.Duration) {
+	dialer, acceptor := self.Dialer, self.Acceptor
+	for _, ends := range [][2]*linkEnd{{dialer, acceptor}, {acceptor, dialer}} {
+		end, other := ends[0], ends[1]
+		if end.State == "up" && other.State != "up" && other.State != "dialing" {
+			self.Issues = append(self.Issues, fmt.Sprintf("up on %v, but %v on %v", end.Node, other.State, other.Node))
+		}
+		if end.Heartbeats > 0 && other.Heartbeats == 0 {
+			self.Issues = append(self.Issues, fmt.Sprintf("%v heartbeat timeouts on %v only", end.Heartbeats, end.Node))
+		}
+	}
+
+	if self.skew = self.estimateSkew(); self.skew != nil {
+		self.Skew = self.skew.String()
+		switch {
+		case *self.skew > maxSkew:
+			self.Issues = append(self.Issues, fmt.Sprintf("%v clock at least %v ahead of %v", acceptor.Node, *self.skew, dialer.Node))
+		case *self.skew < -maxSkew:
+			self.Issues = append(self.Issues, fmt.Sprintf("%v clock at least %v behind %v", acceptor.Node, -*self.skew, dialer.Node))
+		}
+	}
+}
+
+// linkEndsHandler collects the link records for one router without reporting them
+type linkEndsHandler struct {
+	*LinkTimelineHandler
+}
+
+func (self *linkEndsHandler) HandleEnd(*JsonParseContext) {}
+
+// LinkPairer matches the dialing and accepting ends of links across the logs of several routers, and reports where
+// the two ends disagree
+type LinkPairer struct {
+	multiSourceOptions
+	maxSkew time.Duration
+}
+
+func newTraceLinksCmd() *cobra.Command {
+	pairer := &LinkPairer{}
+	traceLinksCmd := &cobra.Command{
+		Use:   "links [<label>=<file>...]",
+		Short: "Pair the dialing and accepting ends of links across router logs and report where they disagree",
+		RunE:  pairer.run,
+	}
+	pairer.addRouterArgs(traceLinksCmd)
+	traceLinksCmd.Flags().DurationVar(&pairer.maxSkew, "max-skew", time.Second, "Report link ends whose clocks differ by more than this")
+	return traceLinksCmd
+}
+
+func (self *LinkPairer) run(cmd *cobra.Command, args []string) error {
+	sources, err := self.getSources(args)
+	if err != nil {
+		return err
+	}
+
+	endsById := map[string][]*linkEnd{}
+	for _, source := range sources {
+		if source.component != "router" {
+			return errors.Errorf("%v holds %v logs, but links can only be paired using router logs", source.label, source.component)
+		}
+		parser, err := self.newParser(source)
+		if err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+
+		handler := &linkEndsHandler{LinkTimelineHandler: NewLinkTimelineHandler(self.formatter)}
+		parser.handler = handler
+		if err = parser.scan(source.inputs); err != nil {
+			return err
+		}
+		for _, link := range handler.getLinks() {
+			endsById[link.Id] = append(endsById[link.Id], &linkEnd{Node: source.label, linkRecord: link})
+		}
+	}
+
+	pairs, unpaired := pairLinkEnds(endsById, self.maxSkew)
+
+	if self.formatter == "json" {
+		dumpLinkPairsJson(pairs, unpaired)
+	} else {
+		dumpLinkPairsText(pairs, unpaired)
+	}
+	return nil
+}
+
+// pairLinkEnds pairs up the ends of each link seen by more than one router and checks where they disagree. Pairs and
+// links seen on one end only are returned in the order they were first seen
+func pairLinkEnds(endsById map[string][]*linkEnd, maxSkew time.Duration) ([]*linkPair, []*linkEnd) {
+	var pairs []*linkPair
+	var unpaired []*linkEnd
+	for id, ends := range endsById {
+		if len(ends) == 1 {
+			unpaired = append(unpaired, ends[0])
+			continue
+		}
+
+		// the dialer is the end which logged dialing the link. If neither did, use the order the logs were given in
+		sort.SliceStable(ends, func(i, j int) bool {
+			return ends[i].Direction == "dialed" && ends[j].Direction != "dialed"
+		})
+		pair := &linkPair{Id: id, Dialer: ends[0], Acceptor: ends[1]}
+		if len(ends) > 2 {
+			var nodes []string
+			for _, end := range ends {
+				nodes = append(nodes, end.Node)
+			}
+			pair.Issues = append(pair.Issues, fmt.Sprintf("seen in %v logs: %v", len(ends), strings.Join(nodes, ", ")))
+		}
+		if pair.Dialer.Direction == pair.Acceptor.Direction && pair.Dialer.Direction != "" {
+			pair.Issues = append(pair.Issues, fmt.Sprintf("both ends %v the link", pair.Dialer.Direction))
+		}
+		pair.check(maxSkew)
+		pairs = append(pairs, pair)
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		iFirst, jFirst := pairs[i].firstSeen(), pairs[j].firstSeen()
+		if iFirst.Equal(jFirst) {
+			return pairs[i].Id < pairs[j].Id
+		}
+		return iFirst.Before(jFirst)
+	})
+	sort.Slice(unpaired, func(i, j int) bool {
+		if unpaired[i].firstSeen.Equal(unpaired[j].firstSeen) {
+			return unpaired[i].Id < unpaired[j].Id
+		}
+		return unpaired[i].firstSeen.Before(unpaired[j].firstSeen)
+	})
+	return pairs, unpaired
+}
+
+func (self *linkPair) firstSeen() time.Time {
+	if self.Acceptor.firstSeen.Before(self.Dialer.firstSeen) {
+		return self.Acceptor.firstSeen
+	}
+	return self.Dialer.firstSeen
+}
+
+func dumpLinkPairsText(pairs []*linkPair, unpaired []*linkEnd) {
+	asymmetric := 0
+	for _, pair := range pairs {
+		if len(pair.Issues) > 0 {
+			asymmetric++
+		}
+	}
+	fmt.Printf("links seen on both ends: %v, with asymmetries: %v, seen on one end only: %v\n\n", len(pairs), asymmetric, len(unpaired))
+
+	if len(pairs) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "LINK\tDIALER\tACCEPTOR\tDIALER STATE\tACCEPTOR STATE\tSKEW\tISSUES")
+		for _, pair := range pairs {
+			skew := pair.Skew
+			if skew == "" {
+				skew = "-"
+			}
+			_, _ = fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", pair.Id, pair.Dialer.Node, pair.Acceptor.Node,
+				pair.Dialer.State, pair.Acceptor.State, skew, strings.Join(pair.Issues, "; "))
+		}
+		_ = w.Flush()
+		fmt.Println()
+	}
+
+	if len(unpaired) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "LINK\tNODE\tDIRECTION\tPEER ROUTER\tSTATE")
+		for _, end := range unpaired {
+			_, _ = fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", end.Id, end.Node, end.Direction, end.PeerRouter, end.State)
+		}
+		_ = w.Flush()
+	}
+}
+
+func dumpLinkPairsJson(pairs []*linkPair, unpaired []*linkEnd) {
+	if pairs == nil {
+		pairs = []*linkPair{}
+	}
+	if unpaired == nil {
+		unpaired = []*linkEnd{}
+	}
+
+	j, err := json.Marshal(map[string]interface{}{
+		"pairs":    pairs,
+		"unpaired": unpaired,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s\n", string(j))
+}
@@ -0,0 +1,80 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// scanLinkEnds records the links in the router log lines as seen from the given node
+func scanLinkEnds(t *testing.T, endsById map[string][]*linkEnd, node string, lines string) {
+	t.Helper()
+	parser := newTestParser(t, "router")
+	handler := &linkEndsHandler{LinkTimelineHandler: NewLinkTimelineHandler("json")}
+	parser.handler = handler
+	if err := parser.scanLines(lines); err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range handler.getLinks() {
+		endsById[link.Id] = append(endsById[link.Id], &linkEnd{Node: node, linkRecord: link})
+	}
+}
+
+// TestLinkPairs checks a link which the accepting router closed while the dialing router still thinks it's up, with
+// heartbeat timeouts logged by the dialer only and an accept time showing the acceptor's clock is ahead
+func TestLinkPairs(t *testing.T) {
+	endsById := map[string][]*linkEnd{}
+	scanLinkEnds(t, endsById, "r1", `{"file":"github.com/openziti/fabric@v0.22.0/router/handler_ctrl/dial.go:90","level":"info","msg":"dialing link","linkId":"9dMe3KeLv","routerId":"r2","time":"2024-03-01T10:00:00Z"}
+{"file":"github.com/openziti/fabric@v0.22.0/router/handler_ctrl/dial.go:104","level":"info","msg":"link established","linkId":"9dMe3KeLv","routerId":"r2","time":"2024-03-01T10:00:01Z"}
+{"file":"github.com/openziti/fabric@v0.22.0/router/handler_link/bind.go:130","level":"warning","msg":"heartbeat not received in time, link may be unhealthy","linkId":"9dMe3KeLv","time":"2024-03-01T10:30:00Z"}
+{"file":"github.com/openziti/fabric@v0.22.0/router/handler_link/bind.go:130","level":"warning","msg":"heartbeat not received in time, link may be unhealthy","linkId":"9dMe3KeLv","time":"2024-03-01T10:31:00Z"}
+{"file":"github.com/openziti/fabric@v0.22.0/router/handler_ctrl/dial.go:90","level":"info","msg":"dialing link","linkId":"4hTf8Wmz","routerId":"r3","time":"2024-03-01T10:40:00Z"}
+`)
+	scanLinkEnds(t, endsById, "r2", `{"file":"github.com/openziti/fabric@v0.22.0/router/xlink_transport/listener.go:95","level":"info","msg":"accepting link","linkId":"9dMe3KeLv","routerId":"r1","time":"2024-03-01T10:00:03Z"}
+{"file":"github.com/openziti/fabric@v0.22.0/router/handler_link/close.go:57","level":"info","msg":"link closed","linkId":"9dMe3KeLv","time":"2024-03-01T10:20:00Z"}
+`)
+
+	pairs, unpaired := pairLinkEnds(endsById, time.Second)
+	if len(pairs) != 1 || len(unpaired) != 1 {
+		t.Fatalf("expected 1 pair and 1 unpaired link, got %v and %v", len(pairs), len(unpaired))
+	}
+	if unpaired[0].Id != "4hTf8Wmz" || unpaired[0].Node != "r1" {
+		t.Errorf("expected 4hTf8Wmz to be seen on r1 only, got %v on %v", unpaired[0].Id, unpaired[0].Node)
+	}
+
+	pair := pairs[0]
+	if pair.Dialer.Node != "r1" || pair.Acceptor.Node != "r2" {
+		t.Errorf("expected r1 to dial r2, got %v dialing %v", pair.Dialer.Node, pair.Acceptor.Node)
+	}
+	if pair.Dialer.State != "up" || pair.Acceptor.State != "closed" {
+		t.Errorf("expected the link to be up on r1 and closed on r2, got %v and %v", pair.Dialer.State, pair.Acceptor.State)
+	}
+	if pair.Skew != "2s" {
+		t.Errorf("expected a skew of 2s, got %v", pair.Skew)
+	}
+
+	expected := []string{
+		"up on r1, but closed on r2",
+		"2 heartbeat timeouts on r1 only",
+		"r2 clock at least 2s ahead of r1",
+	}
+	if actual := strings.Join(pair.Issues, "; "); actual != strings.Join(expected, "; ") {
+		t.Errorf("expected issues %v, got %v", strings.Join(expected, "; "), actual)
+	}
+}
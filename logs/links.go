@@ -0,0 +1,342 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type linkEventType int
+
+const (
+	linkEventDialed linkEventType = iota
+	linkEventAccepted
+	linkEventEstablished
+	linkEventDialFailed
+	linkEventFaulted
+	linkEventClosed
+	linkEventHeartbeatTimeout
+)
+
+// linkEvents maps the router and controller link filters to the lifecycle event they represent
+var linkEvents = map[string]linkEventType{
+	"LINK_DIAL":                linkEventDialed,
+	"LINK_ESTABLISHED":         linkEventEstablished,
+	"LINK_DIAL_FAIL":           linkEventDialFailed,
+	"LINK_ACCEPTED":            linkEventAccepted,
+	"LINK_ACCEPTED1":           linkEventAccepted,
+	"LINK_ACCEPTED2":           linkEventAccepted,
+	"LINK_SPLIT_ACCEPT_FIRST":  linkEventAccepted,
+	"LINK_SPLIT_ACCEPT_SECOND": linkEventAccepted,
+	"LINK_SPLIT_ACCEPTED":      linkEventAccepted,
+	"LINK_FAULT_SENT":          linkEventFaulted,
+	"LINK_CLOSED":              linkEventClosed,
+	"LINK_FAULT":               linkEventFaulted,
+	"LINK_FAILED":              linkEventDialFailed,
+	"LINK_REMOVED":             linkEventClosed,
+	"LINK_HEARBEAT_TIMEOUT":    linkEventHeartbeatTimeout,
+}
+
+var linkIdRegex = regexp.MustCompile(`\bl/([\w.\-]+)`)
+var routerIdRegex = regexp.MustCompile(`\br/([\w.\-]+)`)
+
+// getLinkId returns the link id from the entry fields, falling back to the [l/<id>] notation used in messages and
+// channel labels
+func getLinkId(ctx *JsonParseContext) string {
+	for _, field := range []string{"linkId", "link"} {
+		if v := ctx.GetString(field); v != "" {
+			return v
+		}
+	}
+	for _, field := range []string{"msg", "_context"} {
+		if match := linkIdRegex.FindStringSubmatch(ctx.GetString(field)); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// getPeerRouterId returns the id of the router on the other side of a link, if it was logged
+func getPeerRouterId(ctx *JsonParseContext) string {
+	for _, field := range []string{"routerId", "remoteRouterId", "dialerId"} {
+		if v := ctx.GetString(field); v != "" {
+			return v
+		}
+	}
+	if match := routerIdRegex.FindStringSubmatch(ctx.GetString("msg")); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+type linkRecord struct {
+	Id          string     `json:"id"`
+	Direction   string     `json:"direction,omitempty"`
+	PeerRouter  string     `json:"peerRouter,omitempty"`
+	Dialed      *time.Time `json:"dialed,omitempty"`
+	Established *time.Time `json:"established,omitempty"`
+	Faulted     *time.Time `json:"faulted,omitempty"`
+	Closed      *time.Time `json:"closed,omitempty"`
+	DialFailed  bool       `json:"dialFailed,omitempty"`
+	Heartbeats  int        `json:"heartbeatTimeouts,omitempty"`
+	State       string     `json:"state"`
+	Duration    string     `json:"duration,omitempty"`
+	firstSeen   time.Time
+}
+
+func (self *linkRecord) apply(event linkEventType, t time.Time) {
+	if self.firstSeen.IsZero() || t.Before(self.firstSeen) {
+		self.firstSeen = t
+	}
+	switch event {
+	case linkEventDialed:
+		self.Direction = "dialed"
+		setIfUnset(&self.Dialed, t)
+	case linkEventAccepted:
+		if self.Direction == "" {
+			self.Direction = "accepted"
+		}
+		setIfUnset(&self.Established, t)
+	case linkEventEstablished:
+		self.Direction = "dialed"
+		setIfUnset(&self.Established, t)
+	case linkEventDialFailed:
+		self.DialFailed = true
+		setIfUnset(&self.Faulted, t)
+	case linkEventFaulted:
+		setIfUnset(&self.Faulted, t)
+	case linkEventClosed:
+		setIfUnset(&self.Closed, t)
+	case linkEventHeartbeatTimeout:
+		self.Heartbeats++
+	}
+}
+
+func (self *linkRecord) start() *time.Time {
+	if self.Established != nil {
+		return self.Established
+	}
+	return self.Dialed
+}
+
+func (self *linkRecord) end() *time.Time {
+	if self.Faulted != nil && (self.Closed == nil || self.Faulted.Before(*self.Closed)) {
+		return self.Faulted
+	}
+	return self.Closed
+}
+
+func (self *linkRecord) finish() {
+	switch {
+	case self.DialFailed && self.Established == nil:
+		self.State = "dial-failed"
+	case self.Faulted != nil:
+		self.State = "faulted"
+	case self.Closed != nil:
+		self.State = "closed"
+	case self.Established != nil, self.Dialed == nil:
+		// a link only seen in heartbeat messages was established before the log started
+		self.State = "up"
+	default:
+		self.State = "dialing"
+	}
+
+	if start, end := self.start(), self.end(); start != nil && end != nil {
+		self.Duration = end.Sub(*start).String()
+	}
+}
+
+func setIfUnset(field **time.Time, t time.Time) {
+	if *field == nil {
+		*field = &t
+	}
+}
+
+type linkPeerSummary struct {
+	PeerRouter string `json:"peerRouter"`
+	Links      int    `json:"links"`
+	Faults     int    `json:"faults"`
+	DialFails  int    `json:"dialFailures"`
+	Closed     int    `json:"closed"`
+}
+
+// LinkTimelineHandler reconstructs the lifecycle of each link from the link related filter matches
+type LinkTimelineHandler struct {
+	links     map[string]*linkRecord
+	formatter string
+}
+
+func NewLinkTimelineHandler(formatter string) *LinkTimelineHandler {
+	return &LinkTimelineHandler{
+		links:     map[string]*linkRecord{},
+		formatter: formatter,
+	}
+}
+
+func (self *LinkTimelineHandler) HandleNewLine(*JsonParseContext) error {
+	return nil
+}
+
+func (self *LinkTimelineHandler) HandleMatch(ctx *JsonParseContext, logFilter LogFilter) error {
+	event, found := linkEvents[logFilter.Id()]
+	if !found {
+		return nil
+	}
+
+	linkId := getLinkId(ctx)
+	if linkId == "" {
+		return nil
+	}
+
+	// an entry without a usable timestamp can't be placed in the timeline, but shouldn't stop the rest of the report
+	t, err := ctx.GetTime()
+	if err != nil {
+		return nil
+	}
+
+	link, found := self.links[linkId]
+	if !found {
+		link = &linkRecord{Id: linkId}
+		self.links[linkId] = link
+	}
+
+	if link.PeerRouter == "" {
+		link.PeerRouter = getPeerRouterId(ctx)
+	}
+
+	link.apply(event, t)
+	return nil
+}
+
+func (self *LinkTimelineHandler) HandleUnmatched(*JsonParseContext) error {
+	return nil
+}
+
+// getLinks finishes each link record and returns them in the order they were first seen
+func (self *LinkTimelineHandler) getLinks() []*linkRecord {
+	var links []*linkRecord
+	for _, link := range self.links {
+		link.finish()
+		links = append(links, link)
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].firstSeen.Equal(links[j].firstSeen) {
+			return links[i].Id < links[j].Id
+		}
+		return links[i].firstSeen.Before(links[j].firstSeen)
+	})
+	return links
+}
+
+func (self *LinkTimelineHandler) HandleEnd(*JsonParseContext) {
+	links := self.getLinks()
+	peers := map[string]*linkPeerSummary{}
+	for _, link := range links {
+		peer, found := peers[link.PeerRouter]
+		if !found {
+			peer = &linkPeerSummary{PeerRouter: link.PeerRouter}
+			peers[link.PeerRouter] = peer
+		}
+		peer.Links++
+		if link.Faulted != nil && !link.DialFailed {
+			peer.Faults++
+		}
+		if link.DialFailed {
+			peer.DialFails++
+		}
+		if link.Closed != nil {
+			peer.Closed++
+		}
+	}
+
+	var peerSummaries []*linkPeerSummary
+	for _, peer := range peers {
+		peerSummaries = append(peerSummaries, peer)
+	}
+	sort.Slice(peerSummaries, func(i, j int) bool {
+		if peerSummaries[i].Links == peerSummaries[j].Links {
+			return peerSummaries[i].PeerRouter < peerSummaries[j].PeerRouter
+		}
+		return peerSummaries[i].Links > peerSummaries[j].Links
+	})
+
+	if self.formatter == "json" {
+		self.dumpJson(links, peerSummaries)
+	} else {
+		self.dumpText(links, peerSummaries)
+	}
+}
+
+func (self *LinkTimelineHandler) dumpText(links []*linkRecord, peers []*linkPeerSummary) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "LINK\tDIRECTION\tPEER ROUTER\tDIALED\tESTABLISHED\tFAULTED\tCLOSED\tDURATION\tSTATE")
+	for _, link := range links {
+		_, _ = fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", link.Id, link.Direction, link.PeerRouter,
+			formatOptionalTime(link.Dialed), formatOptionalTime(link.Established), formatOptionalTime(link.Faulted),
+			formatOptionalTime(link.Closed), link.Duration, link.State)
+	}
+	_ = w.Flush()
+
+	fmt.Println()
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PEER ROUTER\tLINKS\tFAULTS\tDIAL FAILURES\tCLOSED")
+	for _, peer := range peers {
+		_, _ = fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", peer.PeerRouter, peer.Links, peer.Faults, peer.DialFails, peer.Closed)
+	}
+	_ = w.Flush()
+}
+
+func (self *LinkTimelineHandler) dumpJson(links []*linkRecord, peers []*linkPeerSummary) {
+	model := map[string]interface{}{
+		"links": links,
+		"peers": peers,
+	}
+
+	j, err := json.Marshal(model)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s\n", string(j))
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func (self *JsonLogsParser) showLinks(_ *cobra.Command, args []string) error {
+	if err := self.validate(); err != nil {
+		return err
+	}
+
+	self.handler = NewLinkTimelineHandler(self.formatter)
+
+	return self.scan(args)
+}
@@ -0,0 +1,72 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"testing"
+)
+
+// TestLinkTimeline checks the lifecycle of a link which was dialed, established, faulted and closed. An entry with a
+// timestamp which can't be parsed is skipped, rather than failing the report
+func TestLinkTimeline(t *testing.T) {
+	parser := newTestParser(t, "router")
+	handler := NewLinkTimelineHandler("json")
+	parser.handler = handler
+
+	lines := `{"file":"github.com/openziti/fabric@v0.22.0/router/handler_ctrl/dial.go:90","level":"info","msg":"dialing link","linkId":"9dMe3KeLv","routerId":"aB9q","time":"2024-03-01T10:00:00Z"}
+{"file":"github.com/openziti/fabric@v0.22.0/router/handler_ctrl/dial.go:104","level":"info","msg":"link established","linkId":"9dMe3KeLv","routerId":"aB9q","time":"2024-03-01T10:00:01Z"}
+{"file":"github.com/openziti/fabric@v0.22.0/router/handler_link/bind.go:130","level":"warning","msg":"heartbeat not received in time, link may be unhealthy","linkId":"9dMe3KeLv","time":"not a time"}
+{"file":"github.com/openziti/fabric@v0.22.0/router/handler_link/close.go:70","level":"info","msg":"transmitted link fault","linkId":"9dMe3KeLv","time":"2024-03-01T11:00:01Z"}
+{"file":"github.com/openziti/fabric@v0.22.0/router/handler_link/close.go:57","level":"info","msg":"link closed","linkId":"9dMe3KeLv","time":"2024-03-01T11:00:02Z"}
+`
+	if err := parser.scanLines(lines); err != nil {
+		t.Fatal(err)
+	}
+
+	links := handler.getLinks()
+	if len(links) != 1 {
+		t.Fatalf("expected 1 link, got %v", len(links))
+	}
+
+	link := links[0]
+	if link.Id != "9dMe3KeLv" || link.Direction != "dialed" || link.PeerRouter != "aB9q" {
+		t.Errorf("expected link 9dMe3KeLv dialed to aB9q, got %v %v to %v", link.Id, link.Direction, link.PeerRouter)
+	}
+	expected := map[string]string{
+		"dialed":      "2024-03-01T10:00:00Z",
+		"established": "2024-03-01T10:00:01Z",
+		"faulted":     "2024-03-01T11:00:01Z",
+		"closed":      "2024-03-01T11:00:02Z",
+	}
+	actual := map[string]string{
+		"dialed":      formatOptionalTime(link.Dialed),
+		"established": formatOptionalTime(link.Established),
+		"faulted":     formatOptionalTime(link.Faulted),
+		"closed":      formatOptionalTime(link.Closed),
+	}
+	for _, name := range getSortedKeys(expected) {
+		if actual[name] != expected[name] {
+			t.Errorf("expected link to be %v at %v, got %v", name, expected[name], actual[name])
+		}
+	}
+	if link.State != "faulted" || link.Duration != "1h0m0s" {
+		t.Errorf("expected link to be faulted after 1h0m0s, got %v after %v", link.State, link.Duration)
+	}
+	if link.Heartbeats != 0 {
+		t.Errorf("expected the heartbeat timeout without a timestamp to be skipped, got %v", link.Heartbeats)
+	}
+}
@@ -0,0 +1,192 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openziti/foundation/v2/stringz"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	lintError   = "ERROR"
+	lintWarning = "WARN"
+)
+
+type lintFinding struct {
+	level    string
+	filterId string
+	message  string
+}
+
+func (self *lintFinding) String() string {
+	return fmt.Sprintf("%v %v: %v", self.level, self.filterId, self.message)
+}
+
+// lintFilters checks the filters for duplicate ids, invalid matchers and version ranges, and uses the samples to
+// find filters which are shadowed by earlier filters. Since the first matching filter wins, a filter whose samples
+// are all matched first by other filters can never fire
+func (self *JsonLogsParser) lintFilters(samples []*filterSample) []*lintFinding {
+	var result []*lintFinding
+	add := func(level string, filterId string, msg string, args ...interface{}) {
+		result = append(result, &lintFinding{level: level, filterId: filterId, message: fmt.Sprintf(msg, args...)})
+	}
+
+	ids := map[string]int{}
+	for idx, logFilter := range self.filters {
+		if prev, found := ids[logFilter.Id()]; found {
+			add(lintError, logFilter.Id(), "duplicate id at indices %v and %v", prev, idx)
+		}
+		ids[logFilter.Id()] = idx
+		if err := validateMatcher(logFilter); err != nil {
+			add(lintError, logFilter.Id(), "%v", err)
+		}
+		if _, err := getVersionRange(logFilter); err != nil {
+			add(lintError, logFilter.Id(), "%v", err)
+		}
+	}
+
+	if err := self.setupVersions(); err != nil {
+		// already reported above
+		return result
+	}
+
+	type sampleCounts struct {
+		total      int
+		shadowedBy map[string]int
+	}
+	counts := map[string]*sampleCounts{}
+
+	for _, sample := range samples {
+		if sample.Filter == "" {
+			continue
+		}
+
+		check := self.checkSample(sample)
+		if check.match == nil {
+			add(lintWarning, sample.Filter, "%v can't be checked: %v", sample, check.failure)
+			continue
+		}
+
+		count := counts[sample.Filter]
+		if count == nil {
+			count = &sampleCounts{shadowedBy: map[string]int{}}
+			counts[sample.Filter] = count
+		}
+		count.total++
+
+		switch {
+		case check.shadowedBy != "":
+			count.shadowedBy[check.shadowedBy]++
+		case check.failure != "":
+			add(lintWarning, sample.Filter, "%v isn't matched by its filter: %v", sample, check.failure)
+		}
+	}
+
+	for _, logFilter := range self.filters {
+		count := counts[logFilter.Id()]
+		if count == nil {
+			add(lintWarning, logFilter.Id(), "no samples, so shadowing can't be checked")
+			continue
+		}
+
+		shadowed := 0
+		var shadowingIds []string
+		for id, n := range count.shadowedBy {
+			shadowed += n
+			shadowingIds = append(shadowingIds, id)
+		}
+		sort.Strings(shadowingIds)
+
+		if shadowed == count.total {
+			add(lintError, logFilter.Id(), "can never fire, all %v samples are matched first by %v", count.total, strings.Join(shadowingIds, ", "))
+		} else if shadowed > 0 {
+			add(lintWarning, logFilter.Id(), "%v of %v samples are matched first by %v", shadowed, count.total, strings.Join(shadowingIds, ", "))
+		}
+	}
+
+	return result
+}
+
+func (self *FilterTester) lint(cmd *cobra.Command, args []string) error {
+	samplesByComponent, err := self.loadSamples(args)
+	if err != nil {
+		return err
+	}
+	// findings from here on aren't usage problems
+	cmd.SilenceUsage = true
+
+	errorCount, warningCount := 0, 0
+	componentsById := map[string][]string{}
+	unsharedIds := map[string]bool{}
+	for _, component := range self.components {
+		parser, err := newComponentParser(component)
+		if err != nil {
+			return err
+		}
+		parser.filterFiles = self.filterFiles
+		if err = parser.loadFilterFiles(); err != nil {
+			return err
+		}
+
+		findings := parser.lintFilters(samplesByComponent[component])
+		for _, logFilter := range parser.filters {
+			if !logFilter.Shared() {
+				unsharedIds[logFilter.Id()] = true
+			}
+			if !stringz.Contains(componentsById[logFilter.Id()], component) {
+				componentsById[logFilter.Id()] = append(componentsById[logFilter.Id()], component)
+			}
+		}
+
+		fmt.Printf("%v: %v filters\n", component, len(parser.filters))
+		for _, finding := range findings {
+			fmt.Printf("    %v\n", finding)
+			if finding.level == lintError {
+				errorCount++
+			} else {
+				warningCount++
+			}
+		}
+	}
+
+	// ids used for more than one component are reported unless every filter with the id is marked as shared
+	var shared []string
+	for id, components := range componentsById {
+		if len(components) > 1 && unsharedIds[id] {
+			shared = append(shared, fmt.Sprintf("%v (%v)", id, strings.Join(components, ", ")))
+		}
+	}
+	if len(shared) > 0 {
+		sort.Strings(shared)
+		fmt.Println("ids defined for more than one component:")
+		for _, s := range shared {
+			fmt.Printf("    %v %v\n", lintWarning, s)
+		}
+		warningCount += len(shared)
+	}
+
+	fmt.Printf("%v errors, %v warnings\n", errorCount, warningCount)
+	if errorCount > 0 {
+		return errors.Errorf("lint found %v errors", errorCount)
+	}
+	return nil
+}
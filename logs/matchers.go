@@ -20,6 +20,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 type LogMatcher interface {
@@ -67,6 +69,23 @@ func (self *OrMatcher) Matches(ctx *JsonParseContext) (bool, error) {
 	return false, nil
 }
 
+// NotMatchers will return a matcher that will match if the supplied matcher doesn't match
+func NotMatchers(matcher LogMatcher) LogMatcher {
+	return &NotMatcher{matcher: matcher}
+}
+
+type NotMatcher struct {
+	matcher LogMatcher
+}
+
+func (self *NotMatcher) Matches(ctx *JsonParseContext) (bool, error) {
+	result, err := self.matcher.Matches(ctx)
+	if err != nil {
+		return false, err
+	}
+	return !result, nil
+}
+
 func FieldStartsWith(field, substring string) LogMatcher {
 	return &EntryFieldStartsWithMatcher{
 		field:  field,
@@ -80,10 +99,17 @@ type EntryFieldStartsWithMatcher struct {
 }
 
 func (self *EntryFieldStartsWithMatcher) Matches(ctx *JsonParseContext) (bool, error) {
+	if self.field == "file" {
+		if pkgDir, ok := ctx.getFuncPackageDir(); ok {
+			return strings.HasPrefix(pkgDir, getFileDir(self.prefix)), nil
+		}
+	}
 	fieldValue := ctx.GetString(self.field)
 	return strings.HasPrefix(fieldValue, self.prefix), nil
 }
 
+// FieldContains will return a matcher that will match if the field contains the substring. For entries which don't log
+// a source file, file matchers check the directory part of the substring against the package of the logged function
 func FieldContains(field, substring string) LogMatcher {
 	return &EntryFieldContainsMatcher{
 		field:     field,
@@ -97,10 +123,38 @@ type EntryFieldContainsMatcher struct {
 }
 
 func (self *EntryFieldContainsMatcher) Matches(ctx *JsonParseContext) (bool, error) {
+	if self.field == "file" {
+		if pkgDir, ok := ctx.getFuncPackageDir(); ok {
+			return strings.Contains(pkgDir, getFileDir(self.substring)), nil
+		}
+	}
 	fieldValue := ctx.GetString(self.field)
 	return strings.Contains(fieldValue, self.substring), nil
 }
 
+// FileOrFunc will return a matcher that will match if the source file contains the given file. Entries which don't
+// log a source file, such as pfxlog text entries, are matched if the function they were logged from contains fn. It's
+// used instead of FieldContains where files in the same package log the same messages
+func FileOrFunc(file, fn string) LogMatcher {
+	return &EntrySourceMatcher{
+		file: file,
+		fn:   fn,
+	}
+}
+
+type EntrySourceMatcher struct {
+	file string
+	fn   string
+}
+
+func (self *EntrySourceMatcher) Matches(ctx *JsonParseContext) (bool, error) {
+	if file := ctx.GetString("file"); file != "" {
+		return strings.Contains(file, self.file), nil
+	}
+	fn := ctx.GetString("func")
+	return fn != "" && strings.Contains(fn, self.fn), nil
+}
+
 func FieldEquals(field, substring string) LogMatcher {
 	return &EntryFieldEqualsMatcher{
 		field: field,
@@ -118,27 +172,76 @@ func (self *EntryFieldEqualsMatcher) Matches(ctx *JsonParseContext) (bool, error
 	return fieldValue == self.value, nil
 }
 
+// FieldMatches will return a matcher that will match if the field matches the regex. If the regex doesn't compile,
+// the error is reported by validateMatcher and by the matcher when it's used
 func FieldMatches(field, expr string) LogMatcher {
 	regex, err := regexp.Compile(expr)
-	if err != nil {
-		panic(err)
-	}
-	return &EntryFieldMatchesMatcher{
+	result := &EntryFieldMatchesMatcher{
 		field: field,
 		regex: regex,
+		err:   err,
 	}
+	if field == "file" && err == nil {
+		// entries without a file are checked against the package using the directory part of the regex, if it
+		// compiles on its own
+		if dirRegex, dirErr := regexp.Compile(getFileDir(expr)); dirErr == nil {
+			result.dirRegex = dirRegex
+		}
+	}
+	return result
 }
 
 type EntryFieldMatchesMatcher struct {
-	field string
-	regex *regexp.Regexp
+	field    string
+	regex    *regexp.Regexp
+	dirRegex *regexp.Regexp
+	err      error
 }
 
 func (self *EntryFieldMatchesMatcher) Matches(ctx *JsonParseContext) (bool, error) {
+	if self.err != nil {
+		return false, errors.Wrapf(self.err, "invalid regex for field %v", self.field)
+	}
+	if self.dirRegex != nil {
+		if pkgDir, ok := ctx.getFuncPackageDir(); ok {
+			return self.dirRegex.MatchString(pkgDir), nil
+		}
+	}
 	fieldValue := ctx.GetString(self.field)
 	return self.regex.MatchString(fieldValue), nil
 }
 
+// validateMatcher returns the first problem found in the matcher or its children, such as a regex which didn't compile
+func validateMatcher(matcher LogMatcher) error {
+	var children []LogMatcher
+	switch m := matcher.(type) {
+	case *filter:
+		for _, extraction := range m.extract {
+			if err := extraction.validate(); err != nil {
+				return err
+			}
+		}
+		children = []LogMatcher{m.LogMatcher}
+	case *AndMatcher:
+		children = m.matchers
+	case *OrMatcher:
+		children = m.matchers
+	case *NotMatcher:
+		children = []LogMatcher{m.matcher}
+	case *EntryFieldMatchesMatcher:
+		if m.err != nil {
+			return errors.Wrapf(m.err, "invalid regex for field %v", m.field)
+		}
+	}
+
+	for _, child := range children {
+		if err := validateMatcher(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type TimePredicate func(t time.Time) bool
 
 func (self TimePredicate) Matches(ctx *JsonParseContext) (bool, error) {
@@ -154,15 +257,10 @@ func (self TimePredicate) Matches(ctx *JsonParseContext) (bool, error) {
 		return self(t), nil
 	}
 
-	if ctx.journaldTimestamp == "" {
+	if ctx.outerTime.IsZero() {
 		return false, nil
 	}
-
-	t, err := ctx.getJournaldTime()
-	if err != nil {
-		return false, err
-	}
-	return self(t), nil
+	return self(ctx.outerTime), nil
 }
 
 type AlwaysMatcher struct{}
@@ -0,0 +1,123 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type mergeSource struct {
+	Label     string   `json:"label"`
+	Component string   `json:"component"`
+	Inputs    []string `json:"inputs"`
+	Entries   int      `json:"entries"`
+}
+
+// LogMerger interleaves the logs of several controllers and routers by time, categorizing each entry using the filters
+// for the component which logged it
+type LogMerger struct {
+	multiSourceOptions
+	severityName string
+	minSeverity  *Severity
+	raw          bool
+}
+
+func newMergeCmd() *cobra.Command {
+	merger := &LogMerger{}
+	mergeCmd := &cobra.Command{
+		Use:   "merge [<label>=<file>...]",
+		Short: "Merge controller and router logs into one categorized timeline, with each entry tagged by its source",
+		RunE:  merger.run,
+	}
+	merger.addArgs(mergeCmd)
+	mergeCmd.Flags().StringVar(&merger.severityName, "min-severity", "", "Only show entries matched by filters with at least this severity: [info|warn|error|critical]")
+	mergeCmd.Flags().BoolVar(&merger.raw, "raw", false, "Output the original lines, prefixed by their source label, instead of a table")
+	return mergeCmd
+}
+
+func (self *LogMerger) run(cmd *cobra.Command, args []string) error {
+	if self.severityName != "" {
+		severity, err := ParseSeverity(self.severityName)
+		if err != nil {
+			return err
+		}
+		self.minSeverity = &severity
+	}
+
+	sources, err := self.getSources(args)
+	if err != nil {
+		return err
+	}
+
+	streams, columns, err := self.startStreams(cmd, sources, nil)
+	if err != nil {
+		return err
+	}
+
+	var summaries []*mergeSource
+	summariesByLabel := map[string]*mergeSource{}
+	for _, source := range sources {
+		summary := &mergeSource{Label: source.label, Component: source.component, Inputs: source.inputs}
+		summaries = append(summaries, summary)
+		summariesByLabel[source.label] = summary
+	}
+
+	// entries are output as they're merged, so that large logs don't have to fit in memory
+	var output func(entry *sourceEntry) error
+	var jsonOutput *jsonEntriesOutput
+	switch {
+	case self.formatter == "json":
+		if jsonOutput, err = startJsonEntries(nil); err != nil {
+			return err
+		}
+		output = jsonOutput.write
+	case self.raw:
+		output = func(entry *sourceEntry) error {
+			prefix := entry.Node + " | "
+			_, err := fmt.Println(prefix + strings.ReplaceAll(entry.line, "\n", "\n"+prefix))
+			return err
+		}
+	default:
+		output = columns.newTextOutput(false)
+	}
+
+	err = mergeSourceStreams(streams, func(entry *sourceEntry) error {
+		if !self.isIncluded(entry) {
+			return nil
+		}
+		summariesByLabel[entry.Node].Entries++
+		return output(entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput != nil {
+		return jsonOutput.finish(map[string]interface{}{"sources": summaries})
+	}
+	return nil
+}
+
+func (self *LogMerger) isIncluded(entry *sourceEntry) bool {
+	if self.minSeverity == nil {
+		return true
+	}
+	return entry.severity != nil && *entry.severity >= *self.minSeverity
+}
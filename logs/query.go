@@ -0,0 +1,271 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// ParseQuery compiles a field query expression into a LogMatcher. Expressions compare entry fields to values using
+// == and != for equality, ~ and !~ for regular expressions, and contains or startsWith for substrings. Comparisons can
+// be combined with &&, || and !, and grouped with parentheses. Values may be quoted with double or single quotes,
+// which is required if they contain spaces or operator characters. For example:
+//
+//	msg ~ "route" && level == error && (circuitId == abc || !file contains "xgress")
+func ParseQuery(query string) (LogMatcher, error) {
+	tokens, err := tokenizeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("query is empty")
+	}
+
+	parser := &queryParser{tokens: tokens}
+	result, err := parser.parseOr()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid query '%v'", query)
+	}
+	if !parser.done() {
+		return nil, errors.Errorf("invalid query '%v': unexpected '%v'", query, parser.peek().value)
+	}
+	return result, nil
+}
+
+type queryTokenType int
+
+const (
+	queryTokenWord queryTokenType = iota
+	queryTokenString
+	queryTokenOperator
+)
+
+type queryToken struct {
+	tokenType queryTokenType
+	value     string
+}
+
+// queryOperators is ordered so that longer operators are checked before their prefixes
+var queryOperators = []string{"&&", "||", "==", "!=", "!~", "~", "!", "(", ")"}
+
+func tokenizeQuery(query string) ([]*queryToken, error) {
+	var result []*queryToken
+	s := query
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if s == "" {
+			return result, nil
+		}
+
+		if s[0] == '"' || s[0] == '\'' {
+			value, rest, err := cutQuoted(s)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, &queryToken{tokenType: queryTokenString, value: value})
+			s = rest
+			continue
+		}
+
+		operator := ""
+		for _, op := range queryOperators {
+			if strings.HasPrefix(s, op) {
+				operator = op
+				break
+			}
+		}
+		if operator != "" {
+			result = append(result, &queryToken{tokenType: queryTokenOperator, value: operator})
+			s = s[len(operator):]
+			continue
+		}
+
+		end := strings.IndexFunc(s, func(r rune) bool {
+			return unicode.IsSpace(r) || strings.ContainsRune(`"'()!=~&|`, r)
+		})
+		if end < 0 {
+			end = len(s)
+		}
+		if end == 0 {
+			return nil, errors.Errorf("invalid query '%v': unexpected '%c'", query, s[0])
+		}
+		result = append(result, &queryToken{tokenType: queryTokenWord, value: s[:end]})
+		s = s[end:]
+	}
+}
+
+// cutQuoted returns the unquoted value of the string at the start of s, and the rest of s. Double quoted strings use
+// go escaping. Single quoted strings have no escapes
+func cutQuoted(s string) (string, string, error) {
+	if s[0] == '\'' {
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", "", errors.Errorf("unterminated string %v", s)
+		}
+		return s[1 : end+1], s[end+2:], nil
+	}
+
+	quoted, err := strconv.QuotedPrefix(s)
+	if err != nil {
+		return "", "", errors.Errorf("unterminated or invalid string %v", s)
+	}
+	value, err := strconv.Unquote(quoted)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "invalid string %v", quoted)
+	}
+	return value, s[len(quoted):], nil
+}
+
+type queryParser struct {
+	tokens []*queryToken
+	pos    int
+}
+
+func (self *queryParser) done() bool {
+	return self.pos >= len(self.tokens)
+}
+
+func (self *queryParser) peek() *queryToken {
+	if self.done() {
+		return nil
+	}
+	return self.tokens[self.pos]
+}
+
+func (self *queryParser) next() *queryToken {
+	result := self.peek()
+	if result != nil {
+		self.pos++
+	}
+	return result
+}
+
+func (self *queryParser) nextIsOperator(op string) bool {
+	token := self.peek()
+	return token != nil && token.tokenType == queryTokenOperator && token.value == op
+}
+
+func (self *queryParser) parseOr() (LogMatcher, error) {
+	matchers, err := self.parseList("||", self.parseAnd)
+	if err != nil || len(matchers) == 1 {
+		return first(matchers), err
+	}
+	return OrMatchers(matchers...), nil
+}
+
+func (self *queryParser) parseAnd() (LogMatcher, error) {
+	matchers, err := self.parseList("&&", self.parseUnary)
+	if err != nil || len(matchers) == 1 {
+		return first(matchers), err
+	}
+	return AndMatchers(matchers...), nil
+}
+
+func (self *queryParser) parseList(separator string, parseElement func() (LogMatcher, error)) ([]LogMatcher, error) {
+	var result []LogMatcher
+	for {
+		matcher, err := parseElement()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, matcher)
+		if !self.nextIsOperator(separator) {
+			return result, nil
+		}
+		self.next()
+	}
+}
+
+func first(matchers []LogMatcher) LogMatcher {
+	if len(matchers) == 0 {
+		return nil
+	}
+	return matchers[0]
+}
+
+func (self *queryParser) parseUnary() (LogMatcher, error) {
+	if self.nextIsOperator("!") {
+		self.next()
+		matcher, err := self.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return NotMatchers(matcher), nil
+	}
+
+	if self.nextIsOperator("(") {
+		self.next()
+		matcher, err := self.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !self.nextIsOperator(")") {
+			return nil, errors.New("missing ')'")
+		}
+		self.next()
+		return matcher, nil
+	}
+
+	return self.parseComparison()
+}
+
+func (self *queryParser) parseComparison() (LogMatcher, error) {
+	field := self.next()
+	if field == nil {
+		return nil, errors.New("expected field name at end of query")
+	}
+	if field.tokenType != queryTokenWord {
+		return nil, errors.Errorf("expected field name, found '%v'", field.value)
+	}
+
+	op := self.next()
+	if op == nil || op.tokenType == queryTokenString {
+		return nil, errors.Errorf("expected operator after field %v", field.value)
+	}
+
+	value := self.next()
+	if value == nil || value.tokenType == queryTokenOperator {
+		return nil, errors.Errorf("expected value after %v %v", field.value, op.value)
+	}
+
+	switch op.value {
+	case "==":
+		return FieldEquals(field.value, value.value), nil
+	case "!=":
+		return NotMatchers(FieldEquals(field.value, value.value)), nil
+	case "~", "!~":
+		regex, err := regexp.Compile(value.value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid regex for field %v", field.value)
+		}
+		var result LogMatcher = &EntryFieldMatchesMatcher{field: field.value, regex: regex}
+		if op.value == "!~" {
+			result = NotMatchers(result)
+		}
+		return result, nil
+	case "contains":
+		return FieldContains(field.value, value.value), nil
+	case "startsWith":
+		return FieldStartsWith(field.value, value.value), nil
+	}
+	return nil, errors.Errorf("unknown operator '%v'. Valid operators: ==, !=, ~, !~, contains, startsWith", op.value)
+}
@@ -0,0 +1,143 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Jeffail/gabs/v2"
+)
+
+func newQueryTestContext() *JsonParseContext {
+	return &JsonParseContext{
+		entry: gabs.Wrap(map[string]interface{}{
+			"level":     "error",
+			"msg":       "route attempt for [s/abc] failed",
+			"circuitId": "abc",
+			"file":      "router/xgress/xgress.go",
+			"note":      "a b",
+			"quote":     `say "hi"`,
+			"path":      `C:\dir`,
+			"expr":      "a&&b||(c)",
+		}),
+		cache: map[string]string{},
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		// operators
+		{`level == error`, true},
+		{`level == info`, false},
+		{`level != info`, true},
+		{`level != error`, false},
+		{`msg ~ "^route attempt"`, true},
+		{`msg ~ "^failed"`, false},
+		{`msg !~ "^route"`, false},
+		{`msg !~ "^failed"`, true},
+		{`file contains xgress`, true},
+		{`file contains link`, false},
+		{`msg startsWith route`, true},
+		{`msg startsWith failed`, false},
+		{`missing == ""`, true},
+
+		// precedence: ! binds tightest, then &&, then ||
+		{`level == info && circuitId == abc || file contains xgress`, true},
+		{`level == error || circuitId == xyz && level == info`, true},
+		{`level == info || circuitId == abc && file contains link`, false},
+		{`!level == info && circuitId == abc`, true},
+		{`!level == error || circuitId == abc`, true},
+		{`!level == error && circuitId == abc`, false},
+		{`!!level == error`, true},
+
+		// parentheses
+		{`level == info && (circuitId == abc || file contains xgress)`, false},
+		{`(level == error || circuitId == xyz) && level == info`, false},
+		{`!(level == error && circuitId == abc)`, false},
+		{`((level == error))`, true},
+
+		// quoting and escaping
+		{`note == "a b"`, true},
+		{`note == 'a b'`, true},
+		{`msg contains "[s/abc]"`, true},
+		{`quote == "say \"hi\""`, true},
+		{`path == 'C:\dir'`, true},
+		{`path == "C:\\dir"`, true},
+		{`expr == "a&&b||(c)"`, true},
+		{`level=="error"&&circuitId=='abc'`, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			matcher, err := ParseQuery(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := matcher.Matches(newQueryTestContext())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{``, `query is empty`},
+		{`   `, `query is empty`},
+		{`level`, `invalid query 'level': expected operator after field level`},
+		{`level ==`, `invalid query 'level ==': expected value after level ==`},
+		{`level == &&`, `invalid query 'level == &&': expected value after level ==`},
+		{`level "error"`, `invalid query 'level "error"': expected operator after field level`},
+		{`== error`, `invalid query '== error': expected field name, found '=='`},
+		{`level == error &&`, `invalid query 'level == error &&': expected field name at end of query`},
+		{`(level == error`, `invalid query '(level == error': missing ')'`},
+		{`level == error)`, `invalid query 'level == error)': unexpected ')'`},
+		{`level == error level == info`, `invalid query 'level == error level == info': unexpected 'level'`},
+		{`level === error`, `invalid query 'level === error': unexpected '='`},
+		{`level is error`, `invalid query 'level is error': unknown operator 'is'. Valid operators: ==, !=, ~, !~, contains, startsWith`},
+		{`msg == "abc`, `unterminated or invalid string "abc`},
+		{`msg == 'abc`, `unterminated string 'abc`},
+		{`msg ~ "("`, `invalid query 'msg ~ "("': invalid regex for field msg: `},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			_, err := ParseQuery(test.query)
+			if err == nil {
+				t.Fatalf("expected error %v", test.expected)
+			}
+			if strings.HasSuffix(test.expected, ": ") {
+				if !strings.HasPrefix(err.Error(), test.expected) {
+					t.Errorf("expected error starting with %v, got %v", test.expected, err)
+				}
+			} else if err.Error() != test.expected {
+				t.Errorf("expected error %v, got %v", test.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// moduleVersionRegex extracts openziti module versions from source paths, ex: github.com/openziti/fabric@v0.22.0/router/...
+var moduleVersionRegex = regexp.MustCompile(`github\.com/openziti/([\w\-]+)(?:/v\d+)?@(v[\w.\-+]+)/`)
+var versionRegex = regexp.MustCompile(`\bv?\d+\.\d+\.\d+[\w.\-+]*`)
+
+// moduleVersionMsgRegex extracts the module and version from the lines logged after the startup banner, ex: ziti-fabric version 0.22.0
+var moduleVersionMsgRegex = regexp.MustCompile(`^ziti-([\w\-]+) version v?(\d+\.\d+\.\d+[\w.\-+]*)`)
+
+// getModuleVersion returns the openziti module and version from the file field of the current entry, if present
+func getModuleVersion(ctx *JsonParseContext) (string, string) {
+	if match := moduleVersionRegex.FindStringSubmatch(ctx.GetString("file")); match != nil {
+		return match[1], match[2]
+	}
+	return "", ""
+}
+
+// isProcessBanner returns true for the startup banner. Module versions from its source path belong to the start it
+// opens, rather than to the current one
+func isProcessBanner(ctx *JsonParseContext) bool {
+	return strings.HasPrefix(ctx.GetString("msg"), "starting ziti-")
+}
+
+type processStart struct {
+	Time           time.Time         `json:"time"`
+	Source         string            `json:"source"`
+	Stopped        *time.Time        `json:"stopped,omitempty"`
+	UptimeBefore   string            `json:"uptimeBeforeRestart,omitempty"`
+	PanicBefore    bool              `json:"precededByPanic"`
+	Version        string            `json:"version,omitempty"`
+	Revision       string            `json:"revision,omitempty"`
+	ModuleVersions map[string]string `json:"moduleVersions,omitempty"`
+	bannerSeen     bool
+}
+
+func (self *processStart) setBanner(ctx *JsonParseContext) {
+	self.bannerSeen = true
+	self.Version = ctx.GetString("version")
+	if self.Version == "" {
+		self.Version = versionRegex.FindString(ctx.GetString("msg"))
+	}
+	self.Revision = ctx.GetString("revision")
+}
+
+func (self *processStart) addModuleVersion(module, version string) {
+	if module == "" {
+		return
+	}
+	if self.ModuleVersions == nil {
+		self.ModuleVersions = map[string]string{}
+	}
+	self.ModuleVersions[module] = version
+}
+
+func (self *processStart) modules() string {
+	var modules []string
+	for module, version := range self.ModuleVersions {
+		modules = append(modules, module+"="+version)
+	}
+	sort.Strings(modules)
+	return strings.Join(modules, " ")
+}
+
+// RestartTimelineHandler reports process starts, using both the startup banner logged by ziti and the systemd
+// Started/Stopped lines, along with the versions that were running after each start
+type RestartTimelineHandler struct {
+	starts          []*processStart
+	current         *processStart
+	stoppedAt       *time.Time
+	panicSinceStart bool
+	formatter       string
+}
+
+func NewRestartTimelineHandler(formatter string) *RestartTimelineHandler {
+	return &RestartTimelineHandler{
+		formatter: formatter,
+	}
+}
+
+func (self *RestartTimelineHandler) HandleNewLine(ctx *JsonParseContext) error {
+	if ctx.entry == nil {
+		return nil
+	}
+
+	if self.current == nil {
+		t, err := ctx.GetTime()
+		if err != nil {
+			return nil
+		}
+		self.current = &processStart{Time: t, Source: "log-start"}
+		self.starts = append(self.starts, self.current)
+	}
+
+	if !isProcessBanner(ctx) {
+		self.current.addModuleVersion(getModuleVersion(ctx))
+	}
+	return nil
+}
+
+func (self *RestartTimelineHandler) HandleMatch(ctx *JsonParseContext, logFilter LogFilter) error {
+	switch logFilter.Id() {
+	case "PANIC_UNKNOWN":
+		self.panicSinceStart = true
+	case "SYSTEMD_STOPPED":
+		if self.stoppedAt == nil {
+			t, err := ctx.GetTime()
+			if err != nil {
+				return err
+			}
+			self.stoppedAt = &t
+		}
+	case "SYSTEMD_STARTED":
+		t, err := ctx.GetTime()
+		if err != nil {
+			return err
+		}
+		self.start(t, "systemd")
+	case "PROCESS_START":
+		// if systemd told us about the start, the banner belongs to the same start
+		if self.current == nil || self.current.Source != "systemd" || self.current.bannerSeen {
+			t, err := ctx.GetTime()
+			if err != nil {
+				return err
+			}
+			self.start(t, "banner")
+		}
+		self.current.setBanner(ctx)
+		self.current.addModuleVersion(getModuleVersion(ctx))
+	case "PROCESS_MODULE_VERSION":
+		// the module versions logged after the banner are details of the current start, not new starts
+		if match := moduleVersionMsgRegex.FindStringSubmatch(ctx.GetString("msg")); match != nil && self.current != nil {
+			self.current.addModuleVersion(match[1], "v"+match[2])
+		}
+	}
+	return nil
+}
+
+func (self *RestartTimelineHandler) start(t time.Time, source string) {
+	start := &processStart{
+		Time:        t,
+		Source:      source,
+		Stopped:     self.stoppedAt,
+		PanicBefore: self.panicSinceStart,
+	}
+
+	// we don't know when the process started if all we've seen is the start of the log. If systemd told us when it
+	// stopped, the time it was down isn't uptime
+	if self.current != nil && self.current.Source != "log-start" {
+		stopped := t
+		if self.stoppedAt != nil {
+			stopped = *self.stoppedAt
+		}
+		start.UptimeBefore = stopped.Sub(self.current.Time).String()
+	}
+
+	// if nothing has been logged yet, there's no need to report an empty log start
+	if self.current != nil && self.current.Source == "log-start" && len(self.starts) == 1 &&
+		self.current.ModuleVersions == nil {
+		self.starts = nil
+	}
+
+	self.starts = append(self.starts, start)
+	self.current = start
+	self.stoppedAt = nil
+	self.panicSinceStart = false
+}
+
+func (self *RestartTimelineHandler) HandleUnmatched(*JsonParseContext) error {
+	return nil
+}
+
+func (self *RestartTimelineHandler) HandleEnd(*JsonParseContext) {
+	if self.formatter == "json" {
+		self.dumpJson()
+	} else {
+		self.dumpText()
+	}
+}
+
+func (self *RestartTimelineHandler) dumpText() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "STARTED\tSOURCE\tSTOPPED\tUPTIME BEFORE\tPANIC BEFORE\tVERSION\tREVISION\tMODULES")
+	for _, start := range self.starts {
+		panicBefore := "no"
+		if start.PanicBefore {
+			panicBefore = "yes"
+		}
+		_, _ = fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", start.Time.Format(time.RFC3339), start.Source,
+			formatOptionalTime(start.Stopped), start.UptimeBefore, panicBefore, start.Version, start.Revision,
+			start.modules())
+	}
+	_ = w.Flush()
+}
+
+func (self *RestartTimelineHandler) dumpJson() {
+	starts := self.starts
+	if starts == nil {
+		starts = []*processStart{}
+	}
+
+	j, err := json.Marshal(map[string]interface{}{
+		"starts": starts,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s\n", string(j))
+}
+
+func (self *JsonLogsParser) showRestarts(_ *cobra.Command, args []string) error {
+	if err := self.validate(); err != nil {
+		return err
+	}
+
+	self.handler = NewRestartTimelineHandler(self.formatter)
+
+	return self.scan(args)
+}
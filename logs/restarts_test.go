@@ -0,0 +1,91 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"testing"
+)
+
+// startupLines is a router start, with the banner followed by the module versions it was built with
+const startupLines = `{"file":"github.com/openziti/ziti/ziti-router/subcmd/run.go:64","level":"info","msg":"starting ziti-router version v1.1.0 of revision 7b2f4c9 (built on 2024-05-01)","time":"2024-05-01T10:00:00Z"}
+{"file":"github.com/openziti/ziti/common/version/info.go:40","level":"info","msg":"ziti-fabric version 0.22.0","time":"2024-05-01T10:00:01Z"}
+{"file":"github.com/openziti/ziti/common/version/info.go:40","level":"info","msg":"ziti-edge version 0.21.30","time":"2024-05-01T10:00:01Z"}
+{"file":"github.com/openziti/ziti/common/version/info.go:40","level":"info","msg":"ziti-foundation version 0.15.0","time":"2024-05-01T10:00:01Z"}
+{"file":"github.com/openziti/channel@v0.18.0/classic_listener.go:80","level":"info","msg":"started listener","time":"2024-05-01T10:00:02Z"}
+`
+
+// TestRestartsModuleVersions checks that the module versions logged after the banner are details of the start the
+// banner opened, rather than starts of their own
+func TestRestartsModuleVersions(t *testing.T) {
+	parser := newTestParser(t, "router")
+	handler := NewRestartTimelineHandler("json")
+	parser.handler = handler
+
+	secondStart := `{"file":"github.com/openziti/ziti/ziti-router/subcmd/run.go:64","level":"info","msg":"starting ziti-router version v1.1.1 of revision 9c3d5e0 (built on 2024-06-01)","time":"2024-05-01T11:00:00Z"}` + "\n"
+	if err := parser.scanLines(startupLines + secondStart); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(handler.starts) != 2 {
+		t.Fatalf("expected 2 starts, got %v", len(handler.starts))
+	}
+
+	first := handler.starts[0]
+	if first.Version != "v1.1.0" {
+		t.Errorf("expected version v1.1.0, got %v", first.Version)
+	}
+	expected := "channel=v0.18.0 edge=v0.21.30 fabric=v0.22.0 foundation=v0.15.0"
+	if modules := first.modules(); modules != expected {
+		t.Errorf("expected modules %v, got %v", expected, modules)
+	}
+
+	second := handler.starts[1]
+	if second.Version != "v1.1.1" || second.UptimeBefore != "1h0m0s" {
+		t.Errorf("expected v1.1.1 after 1h0m0s, got %v after %v", second.Version, second.UptimeBefore)
+	}
+	if second.ModuleVersions != nil {
+		t.Errorf("expected no module versions for the second start, got %v", second.modules())
+	}
+}
+
+// TestRestartsUptimeBeforeStop checks that the uptime before a restart ends when systemd stopped the process, so that
+// the time it was down isn't counted as uptime
+func TestRestartsUptimeBeforeStop(t *testing.T) {
+	parser := newTestParser(t, "router")
+	handler := NewRestartTimelineHandler("json")
+	parser.handler = handler
+
+	lines := `May 01 10:00:00 router1 ziti-router[100]: {"file":"github.com/openziti/ziti/ziti-router/subcmd/run.go:64","level":"info","msg":"starting ziti-router version v1.1.0 of revision 7b2f4c9 (built on 2024-05-01)","time":"2024-05-01T10:00:00Z"}
+May 01 10:30:00 router1 systemd[1]: Stopped Ziti Router.
+May 01 11:00:00 router1 systemd[1]: Started Ziti Router.
+May 01 11:00:01 router1 ziti-router[200]: {"file":"github.com/openziti/ziti/ziti-router/subcmd/run.go:64","level":"info","msg":"starting ziti-router version v1.1.0 of revision 7b2f4c9 (built on 2024-05-01)","time":"2024-05-01T11:00:01Z"}
+`
+	if err := parser.scanLines(lines); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(handler.starts) != 2 {
+		t.Fatalf("expected 2 starts, got %v", len(handler.starts))
+	}
+	second := handler.starts[1]
+	if second.Source != "systemd" || second.Stopped == nil {
+		t.Fatalf("expected a systemd start after a stop, got %v start, stopped at %v", second.Source, second.Stopped)
+	}
+	if second.UptimeBefore != "30m0s" {
+		t.Errorf("expected 30m0s of uptime before the restart, got %v", second.UptimeBefore)
+	}
+}
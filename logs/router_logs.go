@@ -35,6 +35,7 @@ func NewRouterLogsCmd() *cobra.Command {
 		Use:     "filter",
 		Short:   "filter router log entries",
 		Aliases: []string{"f"},
+		Args:    cobra.MinimumNArgs(1),
 		RunE:    routerLogs.filter,
 	}
 
@@ -44,6 +45,7 @@ func NewRouterLogsCmd() *cobra.Command {
 		Use:     "summarize",
 		Short:   "Show router log entry summaries",
 		Aliases: []string{"s"},
+		Args:    cobra.MinimumNArgs(1),
 		RunE:    routerLogs.summarize,
 	}
 
@@ -53,10 +55,70 @@ func NewRouterLogsCmd() *cobra.Command {
 		Use:     "categories",
 		Short:   "Show router log entry categories",
 		Aliases: []string{"cat"},
-		Run:     routerLogs.ShowCategories,
+		RunE:    routerLogs.ShowCategories,
 	}
 
-	parseRouterLogsCmd.AddCommand(filterRouterLogsCmd, summarizeRouterLogsCmd, showRouterLogCategoriesCmd)
+	routerLogs.addFilterSetArgs(showRouterLogCategoriesCmd)
+
+	linksRouterLogsCmd := &cobra.Command{
+		Use:   "links",
+		Short: "Show a timeline of when links were dialed, established, faulted and closed",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  routerLogs.showLinks,
+	}
+
+	routerLogs.addTimelineArgs(linksRouterLogsCmd)
+
+	ctrlChannelRouterLogsCmd := &cobra.Command{
+		Use:     "ctrl-channel",
+		Short:   "Show a timeline of control channel disconnects and reconnects",
+		Aliases: []string{"ctrl"},
+		Args:    cobra.MinimumNArgs(1),
+		RunE:    routerLogs.showCtrlChannel,
+	}
+
+	routerLogs.addTimelineArgs(ctrlChannelRouterLogsCmd)
+
+	terminatorsRouterLogsCmd := &cobra.Command{
+		Use:     "terminators",
+		Short:   "Show a timeline of when terminators were created, updated and removed",
+		Aliases: []string{"term"},
+		Args:    cobra.MinimumNArgs(1),
+		RunE:    routerLogs.showTerminators,
+	}
+
+	routerLogs.addTimelineArgs(terminatorsRouterLogsCmd)
+
+	restartsRouterLogsCmd := &cobra.Command{
+		Use:   "restarts",
+		Short: "Show a timeline of process restarts and the versions running after each restart",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  routerLogs.showRestarts,
+	}
+
+	routerLogs.addTimelineArgs(restartsRouterLogsCmd)
+
+	suggestRouterLogsCmd := &cobra.Command{
+		Use:   "suggest",
+		Short: "Cluster unmatched router log entries into message templates and suggest filters for them",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  routerLogs.suggestFilters,
+	}
+
+	routerLogs.addSuggestArgs(suggestRouterLogsCmd)
+
+	coverageRouterLogsCmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "Show the percentage of router log entries categorized by filters, per level and source file, and which filters never matched",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  routerLogs.showCoverage,
+	}
+
+	routerLogs.addTimelineArgs(coverageRouterLogsCmd)
+	routerLogs.addWhereArgs(coverageRouterLogsCmd)
+
+	parseRouterLogsCmd.AddCommand(filterRouterLogsCmd, summarizeRouterLogsCmd, showRouterLogCategoriesCmd, linksRouterLogsCmd,
+		ctrlChannelRouterLogsCmd, terminatorsRouterLogsCmd, restartsRouterLogsCmd, suggestRouterLogsCmd, coverageRouterLogsCmd)
 	return parseRouterLogsCmd
 }
 
@@ -65,6 +127,7 @@ type RouterLogs struct {
 }
 
 func (self *RouterLogs) Init() {
+	self.component = "router"
 	self.filters = getRouterLogFilters()
 }
 
@@ -113,15 +176,17 @@ func getRouterLogFilters() []LogFilter {
 	// xgress related log messges
 	result = append(result,
 		&filter{
-			id:   "XG_READ_ERR",
-			desc: "read failure on the client or server side of the circuit; will cause the circuit to be torn down",
+			id:       "XG_READ_ERR",
+			desc:     "read failure on the client or server side of the circuit; will cause the circuit to be torn down",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "read failed"),
 				FieldContains("file", "xgress/xgress.go"),
 			)},
 		&filter{
-			id:   "XG_WRITE_ERR",
-			desc: "write failure on the client or server side of the circuit; will cause the circuit to be torn down",
+			id:       "XG_WRITE_ERR",
+			desc:     "write failure on the client or server side of the circuit; will cause the circuit to be torn down",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "write failed"),
 				FieldContains("file", "xgress/xgress.go"),
@@ -142,31 +207,39 @@ func getRouterLogFilters() []LogFilter {
 				FieldContains("file", "xgress/link_send_buffer.go"),
 			)},
 		&filter{
-			id:   "XG_FWD_ERR",
-			desc: "router can't forward a message most likely because the circuit is in the middle of being torn down",
+			id:       "XG_FWD_ERR",
+			desc:     "router can't forward a message most likely because the circuit is in the middle of being torn down",
+			severity: SeverityWarn,
+			extract:  []*FieldExtraction{errorCircuitId},
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "unable to forward"),
 				FieldContains("file", "handler_xgress/receive.go"),
 			)},
 		&filter{
-			id:   "XG_RTX_ERR_NO_DEST",
-			desc: "retransmission failed because the circuit has been torn down since the payload was originally sent",
+			id:       "XG_RTX_ERR_NO_DEST",
+			desc:     "retransmission failed because the circuit has been torn down since the payload was originally sent",
+			severity: SeverityWarn,
+			extract:  []*FieldExtraction{errorCircuitId},
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "unexpected error while retransmitting payload"),
 				FieldStartsWith("error", "cannot forward payload, no destination for "),
 				FieldContains("file", "xgress/retransmitter.go"),
 			)},
 		&filter{
-			id:   "XG_RTX_ERR_NO_FWD_TABLE",
-			desc: "retransmission failed because the circuit has no forwarding table",
+			id:       "XG_RTX_ERR_NO_FWD_TABLE",
+			desc:     "retransmission failed because the circuit has no forwarding table",
+			severity: SeverityWarn,
+			extract:  []*FieldExtraction{errorCircuitId},
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "unexpected error while retransmitting payload"),
 				FieldStartsWith("error", "cannot forward payload, no forward table"),
 				FieldContains("file", "xgress/retransmitter.go"),
 			)},
 		&filter{
-			id:   "XG_START_TIMEOUT",
-			desc: "the terminator side of the xgress was torn down because the start signal wasn't received in time from the initiator",
+			id:       "XG_START_TIMEOUT",
+			desc:     "the terminator side of the xgress was torn down because the start signal wasn't received in time from the initiator",
+			severity: SeverityWarn,
+			extract:  []*FieldExtraction{FieldCaptures("msg", `circuit=\[(?P<circuitId>[^\]]+)\]`)},
 			LogMatcher: AndMatchers(
 				FieldMatches("msg", "xgress.*not started in time, closing"),
 				FieldContains("file", "xgress/xgress.go"),
@@ -183,8 +256,10 @@ func getRouterLogFilters() []LogFilter {
 	// channel messages
 	result = append(result,
 		&filter{
-			id:   "CHANNEL_TLS_ERR_NO_CERT",
-			desc: "a connection attempt was made to a channel TLS listener but no certificate was provided",
+			id:       "CHANNEL_TLS_ERR_NO_CERT",
+			desc:     "a connection attempt was made to a channel TLS listener but no certificate was provided",
+			severity: SeverityWarn,
+			extract:  []*FieldExtraction{helloRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "error receiving hello from "),
 				FieldContains("msg", "tls: client didn't provide a certificate"),
@@ -197,47 +272,54 @@ func getRouterLogFilters() []LogFilter {
 				),
 			)},
 		&filter{
-			id:   "CHANNEL_TLS_ERR_UNKNOWN_CA",
-			desc: "a connection attempt was made to a channel TLS listener but the certificates certificate authority is unknown",
+			id:       "CHANNEL_TLS_ERR_UNKNOWN_CA",
+			desc:     "a connection attempt was made to a channel TLS listener but the certificates certificate authority is unknown",
+			severity: SeverityWarn,
+			extract:  []*FieldExtraction{helloRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "error receiving hello from "),
 				FieldContains("msg", "tls: unknown certificate authority"),
 				FieldContains("file", "channel2/classic_listener.go"),
 			)},
 		&filter{
-			id:   "CHANNEL_TLS_CONN_RESET_BY_PEER",
-			desc: "a connection attempt was made to a channel TLS listener but the connection was reset by the peer",
+			id:      "CHANNEL_TLS_CONN_RESET_BY_PEER",
+			desc:    "a connection attempt was made to a channel TLS listener but the connection was reset by the peer",
+			extract: []*FieldExtraction{helloRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "error receiving hello from "),
 				FieldContains("msg", "read: connection reset by peer"),
 				FieldContains("file", "channel2/classic_listener.go"),
 			)},
 		&filter{
-			id:   "CHANNEL_TLS_ERR_TIMEOUT",
-			desc: "a connection attempt was made to a TLS listener but it timed out",
+			id:      "CHANNEL_TLS_ERR_TIMEOUT",
+			desc:    "a connection attempt was made to a TLS listener but it timed out",
+			extract: []*FieldExtraction{helloRemoteAddr},
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "error receiving hello from [tls:"),
 				FieldContains("msg", "i/o timeout"),
 				FieldContains("file", "channel2/classic_listener.go"),
 			)},
 		&filter{
-			id:   "CHANNEL_LATENCY_TIMEOUT",
-			desc: "a latency ping was sent, but no response was received within the timeout",
+			id:       "CHANNEL_LATENCY_TIMEOUT",
+			desc:     "a latency ping was sent, but no response was received within the timeout",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "latency timeout after "),
 				FieldContains("file", "metrics/latency.go"),
 			)},
 		&filter{
-			id:   "CHANNEL_READ_ERR_PEER_RESET",
-			desc: "channel read failed because the connection was reset by its peer",
+			id:       "CHANNEL_READ_ERR_PEER_RESET",
+			desc:     "channel read failed because the connection was reset by its peer",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "rx error"),
 				FieldContains("msg", "connection reset by peer"),
 				FieldContains("file", "channel2/impl.go"),
 			)},
 		&filter{
-			id:   "CHANNEL_READ_ERR_TIMEOUT",
-			desc: "channel read failed because the connection timed out",
+			id:       "CHANNEL_READ_ERR_TIMEOUT",
+			desc:     "channel read failed because the connection timed out",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "rx error"),
 				FieldContains("msg", "read: connection timed out"),
@@ -258,31 +340,35 @@ func getRouterLogFilters() []LogFilter {
 	// dial egress messages
 	result = append(result,
 		&filter{
-			id:   "DIAL_FAILURE_NO_TERMINATOR",
-			desc: "a circuit path has failed to be completed due to a timeout",
+			id:       "DIAL_FAILURE_NO_TERMINATOR",
+			desc:     "a circuit path has failed to be completed due to a timeout",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "failure while handling route update"),
 				FieldContains("file", "handler_ctrl/route.go"),
 			)},
 		&filter{
-			id:   "EGRESS_DIAL_ERR_BIND_FAIL",
-			desc: "the dial failed because the requested address could not be assigned",
+			id:       "EGRESS_DIAL_ERR_BIND_FAIL",
+			desc:     "the dial failed because the requested address could not be assigned",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldEquals("msg", "failed to connect egress"),
 				FieldContains("file", "handler_ctrl/route.go"),
 				FieldContains("error", "bind: cannot assign requested address"),
 			)},
 		&filter{
-			id:   "EGRESS_DIAL_ERR_CONN_REFUSED",
-			desc: "the dial failed because the dialed server refused the connection",
+			id:       "EGRESS_DIAL_ERR_CONN_REFUSED",
+			desc:     "the dial failed because the dialed server refused the connection",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldEquals("msg", "failed to connect egress"),
 				FieldContains("file", "handler_ctrl/route.go"),
 				FieldContains("error", "connect: connection refused"),
 			)},
 		&filter{
-			id:   "TERMINATOR_REMOVAL_FAILED",
-			desc: "a terminator failed to be removed after the edge session was removed",
+			id:       "TERMINATOR_REMOVAL_FAILED",
+			desc:     "a terminator failed to be removed after the edge session was removed",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "failed to remove terminator after edge session was removed"),
 				OrMatchers(
@@ -291,8 +377,9 @@ func getRouterLogFilters() []LogFilter {
 				),
 			)},
 		&filter{
-			id:   "CIRCUIT_ERROR",
-			desc: "a circuit failed to be created",
+			id:       "CIRCUIT_ERROR",
+			desc:     "a circuit failed to be created",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "failure creating circuit"),
 				OrMatchers(
@@ -302,32 +389,76 @@ func getRouterLogFilters() []LogFilter {
 			)},
 	)
 
+	// terminators
+	result = append(result,
+		&filter{
+			id:     "TERMINATOR_CREATED",
+			desc:   "a terminator was created for a service hosted by this router",
+			shared: true,
+			LogMatcher: AndMatchers(
+				OrMatchers(
+					FieldContains("file", "xgress_edge/"),
+					FieldContains("file", "xgress_edge_tunnel/"),
+				),
+				FieldMatches("msg", "^(created|established|registered) (new )?terminator"),
+			)},
+		&filter{
+			id:     "TERMINATOR_UPDATED",
+			desc:   "a terminator for a service hosted by this router was updated, usually to change precedence or cost",
+			shared: true,
+			LogMatcher: AndMatchers(
+				OrMatchers(
+					FieldContains("file", "xgress_edge/"),
+					FieldContains("file", "xgress_edge_tunnel/"),
+				),
+				FieldStartsWith("msg", "updated terminator"),
+			)},
+		&filter{
+			id:     "TERMINATOR_REMOVED",
+			desc:   "a terminator for a service hosted by this router was removed",
+			shared: true,
+			LogMatcher: AndMatchers(
+				OrMatchers(
+					FieldContains("file", "xgress_edge/"),
+					FieldContains("file", "xgress_edge_tunnel/"),
+				),
+				OrMatchers(
+					FieldStartsWith("msg", "removed terminator"),
+					FieldStartsWith("msg", "terminator removed"),
+				),
+			)},
+	)
+
 	// link messages
 	result = append(result,
 		&filter{
-			id:   "LINK_HEARBEAT_TIMEOUT",
-			desc: "a latency probe has failed to be received in time",
+			id:       "LINK_HEARBEAT_TIMEOUT",
+			desc:     "a latency probe has failed to be received in time",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "heartbeat not received in time, link may be unhealthy"),
 				FieldContains("file", "handler_link/bind.go"),
 			)},
 		&filter{
-			id:   "LINK_QUEUE_FULL",
-			desc: "a latency probe has failed to be sent due to a full queue",
+			id:       "LINK_QUEUE_FULL",
+			desc:     "a latency probe has failed to be sent due to a full queue",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "unable to check queue time, too many check already running"),
 				FieldContains("file", "handler_link/bind.go"),
 			)},
 		&filter{
-			id:   "LINK_HEARBEAT_FAIL",
-			desc: "a hearbeat has failed to be sent",
+			id:       "LINK_HEARBEAT_FAIL",
+			desc:     "a hearbeat has failed to be sent",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "failed to send heartbeat"),
 				FieldContains("file", "/heartbeater.go"),
 			)},
 		&filter{
-			id:   "LINK_DIAL_FAIL",
-			desc: "a link dial action has failed",
+			id:       "LINK_DIAL_FAIL",
+			desc:     "a link dial action has failed",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "link dialing failed"),
 				FieldContains("file", "handler_ctrl/dial.go"),
@@ -361,29 +492,33 @@ func getRouterLogFilters() []LogFilter {
 				FieldContains("file", "handler_ctrl/dial.go"),
 			)},
 		&filter{
-			id:   "LINK_DIAL_PAYLOAD",
-			desc: "dialing the link payload channel of a link",
+			id:      "LINK_DIAL_PAYLOAD",
+			desc:    "dialing the link payload channel of a link",
+			extract: []*FieldExtraction{linkIdRef},
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "dialing payload channel for"),
 				FieldContains("file", "xlink_transport/dialer.go"),
 			)},
 		&filter{
-			id:   "LINK_DIAL_ACK",
-			desc: "dialing the link ack channel of a link",
+			id:      "LINK_DIAL_ACK",
+			desc:    "dialing the link ack channel of a link",
+			extract: []*FieldExtraction{linkIdRef},
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "dialing ack channel for"),
 				FieldContains("file", "xlink_transport/dialer.go"),
 			)},
 		&filter{
-			id:   "LINK_CLOSED",
-			desc: "a router to router link was closed",
+			id:       "LINK_CLOSED",
+			desc:     "a router to router link was closed",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldEquals("msg", "link closed"),
 				FieldContains("file", "handler_link/close.go"),
 			)},
 		&filter{
-			id:   "LINK_FAULT_SENT",
-			desc: "the router notified the controller that a link failed",
+			id:       "LINK_FAULT_SENT",
+			desc:     "the router notified the controller that a link failed",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldEquals("msg", "transmitted link fault"),
 				FieldContains("file", "handler_link/close.go"),
@@ -425,8 +560,9 @@ func getRouterLogFilters() []LogFilter {
 				FieldContains("file", "xlink_transport/listener.go"),
 			)},
 		&filter{
-			id:   "LINK_ACCEPTED2",
-			desc: "another router has dialed this router and a link has been established",
+			id:      "LINK_ACCEPTED2",
+			desc:    "another router has dialed this router and a link has been established",
+			extract: []*FieldExtraction{linkIdRef},
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "accepted new link"),
 				FieldContains("file", "router/bind.go"),
@@ -446,8 +582,9 @@ func getRouterLogFilters() []LogFilter {
 				FieldContains("file", "handler_link/control.go"),
 			)},
 		&filter{
-			id:   "LINK_VERIFY_SUCCESS",
-			desc: "link was successfully verified with the controller",
+			id:      "LINK_VERIFY_SUCCESS",
+			desc:    "link was successfully verified with the controller",
+			extract: []*FieldExtraction{linkIdRef},
 			LogMatcher: AndMatchers(
 				FieldContains("file", "handler_link/bind.go"),
 				OrMatchers(
@@ -460,24 +597,27 @@ func getRouterLogFilters() []LogFilter {
 	// control channel
 	result = append(result,
 		&filter{
-			id:   "CTRL_CH_METRICS_SEND_FAILED",
-			desc: "the router failed to send a metrics message to the controller",
+			id:       "CTRL_CH_METRICS_SEND_FAILED",
+			desc:     "the router failed to send a metrics message to the controller",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldContains("msg", "failed to send metrics message"),
 				FieldContains("file", "metrics/ctrl_reporter.go"),
 			)},
 		&filter{
-			id:   "CTRL_CH_RECONNECT_START",
-			desc: "the router to controller control channel connection died and the router trying to reconnect",
+			id:       "CTRL_CH_RECONNECT_START",
+			desc:     "the router to controller control channel connection died and the router trying to reconnect",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldContains("msg", "starting reconnection process"),
-				FieldContains("file", "channel2/reconnecting_impl.go"),
+				FileOrFunc("channel2/reconnecting_impl.go", "channel2.(*reconnectingImpl)"),
 			)},
 		&filter{
-			id:   "CTRL_CH_RECONNECT_ERR",
-			desc: "the router attempted to reconnect the control channel and failed",
+			id:       "CTRL_CH_RECONNECT_ERR",
+			desc:     "the router attempted to reconnect the control channel and failed",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
-				FieldContains("file", "channel2/reconnecting_dialer.go"),
+				FileOrFunc("channel2/reconnecting_dialer.go", "channel2.(*reconnectingDialer)"),
 				FieldMatches("msg", "reconnection attempt.*failed"),
 			)},
 		&filter{
@@ -485,7 +625,16 @@ func getRouterLogFilters() []LogFilter {
 			desc: "the router attempted to reconnect the control channel and succeeded",
 			LogMatcher: AndMatchers(
 				FieldEquals("msg", "reconnected"),
+				FileOrFunc("channel2/reconnecting_impl.go", "channel2.(*reconnectingImpl)"),
+			)},
+		&filter{
+			id:       "CTRL_CH_RECONNECT_PING_FAILED",
+			desc:     "the router control channel ping loop failed to ping the controller and is starting a reconnect",
+			severity: SeverityError,
+			LogMatcher: AndMatchers(
 				FieldContains("file", "channel2/reconnecting_impl.go"),
+				FieldContains("func", "pingInstance"),
+				FieldStartsWith("msg", "ping failed"),
 			)},
 		&filter{
 			id:   "CTRL_CH_RECONNECT_PING",
@@ -495,11 +644,12 @@ func getRouterLogFilters() []LogFilter {
 				FieldContains("func", "pingInstance"),
 			)},
 		&filter{
-			id:   "CTRL_CH_RECONNECT_PING_ERR",
-			desc: "the router control channel ping failed",
+			id:       "CTRL_CH_RECONNECT_PING_ERR",
+			desc:     "the router control channel ping failed",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldContains("msg", "unable to ping"),
-				FieldContains("file", "channel2/reconnecting_dialer.go"),
+				FileOrFunc("channel2/reconnecting_dialer.go", "channel2.(*reconnectingDialer)"),
 			)},
 		&filter{
 			id:   "CTRL_CH_EDGE_HELLO",
@@ -567,15 +717,17 @@ func getRouterLogFilters() []LogFilter {
 			)},
 
 		&filter{
-			id:   "TUNNEL_DIAL_ERR",
-			desc: "a router embedded tunneler failed to create a circuit",
+			id:       "TUNNEL_DIAL_ERR",
+			desc:     "a router embedded tunneler failed to create a circuit",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldEquals("msg", "failed to dial fabric"),
 				FieldContains("file", "xgress_edge_tunnel/fabric.go"),
 			)},
 		&filter{
-			id:   "TUNNEL_DIAL_ERR_TIMEOUT",
-			desc: "a router embedded tunneler failed to create a circuit because the request timed out",
+			id:       "TUNNEL_DIAL_ERR_TIMEOUT",
+			desc:     "a router embedded tunneler failed to create a circuit because the request timed out",
+			severity: SeverityWarn,
 			LogMatcher: AndMatchers(
 				FieldEquals("msg", "tunnel failed"),
 				FieldStartsWith("error", "timed out after"),
@@ -588,9 +740,11 @@ func getRouterLogFilters() []LogFilter {
 				FieldStartsWith("msg", "received datagram from"),
 				FieldContains("file", "tproxy/tproxy_linux.go"),
 			)},
+		// older wording of the above, removed post 0.24.2
 		&filter{
-			id:   "TUNNEL_UDP_READ_EVENT-2",
-			desc: "a router embedded tunneler received a UDP packet",
+			id:         "TUNNEL_UDP_READ_EVENT-2",
+			desc:       "a router embedded tunneler received a UDP packet",
+			maxVersion: "0.24.2",
 			LogMatcher: AndMatchers(
 				FieldContains("file", "tproxy/tproxy_linux.go"),
 				FieldMatches("msg", "received.*bytes for conn"),
@@ -603,8 +757,9 @@ func getRouterLogFilters() []LogFilter {
 				FieldContains("file", "tproxy/tproxy_linux.go"),
 			)},
 		&filter{
-			id:   "TUNNEL_FAILED",
-			desc: "a router embedded tunneler failed to establish a circuit for a tunnel",
+			id:       "TUNNEL_FAILED",
+			desc:     "a router embedded tunneler failed to establish a circuit for a tunnel",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldEquals("msg", "tunnel failed"),
 				FieldContains("file", "tunnel/tunnel.go"),
@@ -614,21 +769,66 @@ func getRouterLogFilters() []LogFilter {
 	// circuit routing messages
 	result = append(result,
 		&filter{
-			id:   "ROUTE_TIMEOUT",
-			desc: "a circuit path has failed to be completed due to a timeout",
+			id:       "ROUTE_TIMEOUT",
+			desc:     "a circuit path has failed to be completed due to a timeout",
+			severity: SeverityError,
+			shared:   true,
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "send response failed"),
 				FieldContains("file", "handler_ctrl/route.go"),
 			)},
 		&filter{
-			id:   "ROUTE_HANDLER_QUEUE_ERROR",
-			desc: "a route update has failed to be queued",
+			id:       "ROUTE_HANDLER_QUEUE_ERROR",
+			desc:     "a route update has failed to be queued",
+			severity: SeverityError,
 			LogMatcher: AndMatchers(
 				FieldStartsWith("msg", "error queuing route processing to pool"),
 				FieldContains("file", "handler_ctrl/route.go"),
 			)},
 	)
 
+	// process lifecycle
+	result = append(result,
+		&filter{
+			id:         "PROCESS_START",
+			desc:       "the router process started and logged its version",
+			shared:     true,
+			LogMatcher: FieldStartsWith("msg", "starting ziti-router"),
+		},
+		&filter{
+			id:         "PROCESS_MODULE_VERSION",
+			desc:       "the router logged the version of an openziti module it was built with, after starting",
+			shared:     true,
+			LogMatcher: FieldMatches("msg", `^ziti-[\w\-]+ version`),
+		},
+		&filter{
+			id:         "SYSTEMD_STARTED",
+			desc:       "systemd reported that it started a unit",
+			shared:     true,
+			LogMatcher: FieldStartsWith("systemd", "Started "),
+		},
+		&filter{
+			id:       "SYSTEMD_STOPPED",
+			desc:     "systemd reported that it stopped a unit or that the unit's process exited",
+			severity: SeverityWarn,
+			shared:   true,
+			LogMatcher: OrMatchers(
+				FieldStartsWith("systemd", "Stopped "),
+				FieldContains("systemd", "Main process exited"),
+			)},
+	)
+
+	// panics
+	result = append(result,
+		&filter{
+			id:         "PANIC_UNKNOWN",
+			desc:       "uncategorized panic",
+			severity:   SeverityCritical,
+			shared:     true,
+			LogMatcher: FieldContains("nonJson", "panic"),
+		},
+	)
+
 	return result
 }
 
@@ -641,11 +841,15 @@ func (self *RouterLogs) summarize(_ *cobra.Command, args []string) error {
 		bucketSize:                  self.bucketSize,
 		bucketMatches:               map[LogFilter]int{},
 		maxUnmatchedLoggedPerBucket: self.maxUnmatched,
-		ignore:                      self.ignore,
+		selector:                    self.selector,
 		formatter:                   self.formatter,
+		minSeverity:                 self.minSeverity,
+		color:                       useColor(),
+		groupBy:                     self.groupBy,
+		groupLimit:                  self.groupLimit,
 	}
 
-	return ScanJsonLines(args[0], self.processLogEntry)
+	return self.scan(args)
 }
 
 func (self *RouterLogs) filter(_ *cobra.Command, args []string) error {
@@ -655,8 +859,12 @@ func (self *RouterLogs) filter(_ *cobra.Command, args []string) error {
 
 	self.handler = &LogFilterHandler{
 		maxUnmatched: self.maxUnmatched,
-		include:      self.includeFilters,
+		selector:     self.selector,
+		hasSelection: len(self.includeFilters) > 0 || len(self.ignore) > 0,
+		printAll:     self.where != "" && len(self.includeFilters) == 0 && len(self.ignore) == 0 && self.minSeverity == nil,
+		minSeverity:  self.minSeverity,
+		color:        useColor(),
 	}
 
-	return ScanJsonLines(args[0], self.processLogEntry)
+	return self.scan(args)
 }
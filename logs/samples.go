@@ -0,0 +1,427 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openziti/foundation/v2/stringz"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// builtInSamples holds the sample corpus for the built-in filters, one file per component
+//
+//go:embed samples/*.yml
+var builtInSamples embed.FS
+
+var sampleComponents = []string{"router", "controller", "endpoint"}
+
+// filterSampleFileDef is the top level of a sample corpus file. Each sample is a log line, along with the id of the
+// filter expected to match it first. Samples without a filter are expected not to match any filter
+type filterSampleFileDef struct {
+	Component string          `yaml:"component"`
+	Samples   []*filterSample `yaml:"samples"`
+}
+
+// filterSample gives either the raw line, which may be in any of the input formats, or the fields of a json entry.
+// If a version is given, only filters for that ziti version are active. Fields gives the values the filter is
+// expected to extract from the entry
+type filterSample struct {
+	Filter  string                 `yaml:"filter"`
+	Version string                 `yaml:"version"`
+	Line    string                 `yaml:"line"`
+	Entry   map[string]interface{} `yaml:"entry"`
+	Fields  map[string]string      `yaml:"fields"`
+	source  string
+}
+
+func (self *filterSample) getLine() (string, error) {
+	if (self.Line == "") == (self.Entry == nil) {
+		return "", errors.New("sample must have exactly one of line, entry")
+	}
+	if self.Line != "" {
+		return self.Line, nil
+	}
+	data, err := json.Marshal(self.Entry)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (self *filterSample) String() string {
+	if self.Filter == "" {
+		return fmt.Sprintf("unmatched sample from %v", self.source)
+	}
+	return fmt.Sprintf("%v sample from %v", self.Filter, self.source)
+}
+
+func parseSampleFile(source string, data []byte) (*filterSampleFileDef, error) {
+	fileDef := &filterSampleFileDef{}
+	if err := yaml.Unmarshal(data, fileDef); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse sample file %v", source)
+	}
+	if !stringz.Contains(sampleComponents, fileDef.Component) {
+		return nil, errors.Errorf("sample file %v has invalid component '%v'. Valid components: %v", source,
+			fileDef.Component, strings.Join(sampleComponents, ", "))
+	}
+	for idx, sample := range fileDef.Samples {
+		if sample == nil {
+			return nil, errors.Errorf("sample at index %v in %v is empty", idx, source)
+		}
+		sample.source = fmt.Sprintf("%v[%v]", source, idx)
+	}
+	return fileDef, nil
+}
+
+// getBuiltInSamples returns the samples for the built-in filters of the given component
+func getBuiltInSamples(component string) ([]*filterSample, error) {
+	source := "samples/" + component + ".yml"
+	data, err := builtInSamples.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	fileDef, err := parseSampleFile(source, data)
+	if err != nil {
+		return nil, err
+	}
+	return fileDef.Samples, nil
+}
+
+func getSortedKeys(m map[string]string) []string {
+	var result []string
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// newComponentParser returns a parser with the built-in filters for the given component
+func newComponentParser(component string) (*JsonLogsParser, error) {
+	switch component {
+	case "router":
+		result := &RouterLogs{}
+		result.Init()
+		return &result.JsonLogsParser, nil
+	case "controller":
+		result := &ControllerLogs{}
+		result.Init()
+		return &result.JsonLogsParser, nil
+	case "endpoint":
+		result := &EndpointLogs{}
+		result.Init()
+		return &result.JsonLogsParser, nil
+	}
+	return nil, errors.Errorf("invalid component '%v'. Valid components: %v", component, strings.Join(sampleComponents, ", "))
+}
+
+// sampleMatch holds the filters matching an entry, in filter order, along with the fields they extracted
+type sampleMatch struct {
+	filters []LogFilter
+	fields  map[string]string
+}
+
+func (self *sampleMatch) getIds() []string {
+	var result []string
+	for _, logFilter := range self.filters {
+		result = append(result, logFilter.Id())
+	}
+	return result
+}
+
+// sampleHandler records the filters matching each entry
+type sampleHandler struct {
+	results []*sampleMatch
+}
+
+func (self *sampleHandler) HandleNewLine(*JsonParseContext) error {
+	return nil
+}
+
+func (self *sampleHandler) HandleMatch(ctx *JsonParseContext, logFilter LogFilter) error {
+	return self.HandleMatches(ctx, []LogFilter{logFilter})
+}
+
+func (self *sampleHandler) HandleMatches(ctx *JsonParseContext, logFilters []LogFilter) error {
+	self.results = append(self.results, &sampleMatch{filters: logFilters, fields: ctx.extracted})
+	return nil
+}
+
+func (self *sampleHandler) HandleUnmatched(*JsonParseContext) error {
+	self.results = append(self.results, &sampleMatch{})
+	return nil
+}
+
+func (self *sampleHandler) HandleEnd(*JsonParseContext) {}
+
+// sampleFailure describes a sample which wasn't matched first by the filter it's meant for
+type sampleFailure struct {
+	sample *filterSample
+	reason string
+}
+
+func (self *sampleFailure) String() string {
+	return fmt.Sprintf("%v: %v", self.sample, self.reason)
+}
+
+// matchSample runs the sample line through the same parsing as a log file, checking it against every active filter.
+// It returns the filters matching the first entry in the sample
+func (self *JsonLogsParser) matchSample(sample *filterSample) (*sampleMatch, error) {
+	line, err := sample.getLine()
+	if err != nil {
+		return nil, err
+	}
+
+	self.version = nil
+	if sample.Version != "" {
+		if self.version, err = parseZitiVersion(sample.Version); err != nil {
+			return nil, err
+		}
+	}
+	self.updateActiveFilters()
+
+	handler := &sampleHandler{}
+	self.handler = handler
+	self.allMatches = true
+	self.include = AlwaysMatcher{}
+
+	if err = self.scanLines(line); err != nil {
+		return nil, err
+	}
+
+	if len(handler.results) == 0 {
+		return nil, errors.New("sample doesn't contain a log entry")
+	}
+	return handler.results[0], nil
+}
+
+// scanLines runs the lines through the same parsing as a log file, passing the entries to the current handler
+func (self *JsonLogsParser) scanLines(lines string) error {
+	ctx := newJsonParseContext(nil, &InputOptions{Format: InputFormatAuto, Location: time.UTC})
+	ctx.format = InputFormatAuto
+	callback := ctx.parseLine(self.processLogEntry)
+	if err := scanReader(&ctx.ParseContext, strings.NewReader(lines), callback); err != nil {
+		return err
+	}
+	ctx.eof = true
+	return callback(&ctx.ParseContext)
+}
+
+// sampleCheck is the result of checking a sample against the filters. If the filter named by the sample matched it,
+// but an earlier filter matched it first, shadowedBy is the earlier filter
+type sampleCheck struct {
+	match      *sampleMatch
+	shadowedBy string
+	failure    string
+}
+
+// checkSample checks that the sample is matched first by the filter it names. A sample fails if no filter matches
+// it, if another filter matches it instead, or if the expected filter matches but is shadowed by an earlier filter.
+// It's shared by test-filters and lint, so they agree on which filters are shadowed
+func (self *JsonLogsParser) checkSample(sample *filterSample) *sampleCheck {
+	result := &sampleCheck{}
+	fail := func(reason string, args ...interface{}) *sampleCheck {
+		result.failure = fmt.Sprintf(reason, args...)
+		return result
+	}
+
+	known, expectedActive := false, false
+	for _, logFilter := range self.filters {
+		known = known || logFilter.Id() == sample.Filter
+	}
+	if sample.Filter != "" && !known {
+		return fail("unknown filter")
+	}
+
+	var err error
+	if result.match, err = self.matchSample(sample); err != nil {
+		return fail("%v", err)
+	}
+
+	for _, logFilter := range self.activeFilters {
+		expectedActive = expectedActive || logFilter.Id() == sample.Filter
+	}
+
+	matchedIds := result.match.getIds()
+
+	switch {
+	case sample.Filter == "" && len(matchedIds) > 0:
+		return fail("expected no match, but matched %v", strings.Join(matchedIds, ", "))
+	case sample.Filter == "":
+	case !expectedActive:
+		return fail("filter isn't active for version '%v'", sample.Version)
+	case len(matchedIds) == 0:
+		return fail("not matched by any filter")
+	case matchedIds[0] == sample.Filter:
+		for _, name := range getSortedKeys(sample.Fields) {
+			if actual := result.match.fields[name]; actual != sample.Fields[name] {
+				return fail("expected field %v to be extracted as '%v', got '%v'", name, sample.Fields[name], actual)
+			}
+		}
+	case stringz.Contains(matchedIds, sample.Filter):
+		result.shadowedBy = matchedIds[0]
+		return fail("shadowed by earlier filter %v", matchedIds[0])
+	default:
+		return fail("matched by %v instead", strings.Join(matchedIds, ", "))
+	}
+	return result
+}
+
+// testSamples checks that each sample is matched first by the filter it names. The filters should already be loaded
+// and their versions set up
+func (self *JsonLogsParser) testSamples(samples []*filterSample) []*sampleFailure {
+	var result []*sampleFailure
+	for _, sample := range samples {
+		if check := self.checkSample(sample); check.failure != "" {
+			result = append(result, &sampleFailure{sample: sample, reason: check.failure})
+		}
+	}
+	return result
+}
+
+// getFiltersWithoutSamples returns the ids of the filters which no sample expects to match
+func (self *JsonLogsParser) getFiltersWithoutSamples(samples []*filterSample) []string {
+	sampled := map[string]struct{}{}
+	for _, sample := range samples {
+		sampled[sample.Filter] = struct{}{}
+	}
+
+	var result []string
+	for _, logFilter := range self.filters {
+		if _, found := sampled[logFilter.Id()]; !found {
+			result = append(result, logFilter.Id())
+		}
+	}
+	return result
+}
+
+// FilterTester checks the filters for each component using the sample corpus for the built-in filters, along with any
+// additional sample files
+type FilterTester struct {
+	components  []string
+	filterFiles []string
+}
+
+func NewLogsCmd() *cobra.Command {
+	logsCmd := &cobra.Command{
+		Use:   "logs",
+		Short: "work with the filters shared by the log commands",
+	}
+
+	tester := &FilterTester{}
+	testFiltersCmd := &cobra.Command{
+		Use:   "test-filters [sample files]",
+		Short: "Check that filters match their sample log lines and aren't shadowed by earlier filters",
+		RunE:  tester.run,
+	}
+	tester.addArgs(testFiltersCmd)
+
+	lintCmd := &cobra.Command{
+		Use:   "lint [sample files]",
+		Short: "Check filters for invalid definitions, filters shadowed by earlier filters and ids shared between components",
+		RunE:  tester.lint,
+	}
+	tester.addArgs(lintCmd)
+
+	logsCmd.AddCommand(testFiltersCmd, lintCmd, newMergeCmd())
+	return logsCmd
+}
+
+func (self *FilterTester) addArgs(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVarP(&self.components, "component", "c", sampleComponents, "Components whose filters should be checked")
+	cmd.Flags().StringSliceVar(&self.filterFiles, "filters-file", nil, "YAML or JSON files with additional filters or overrides of built-in filters. Requires a single component")
+}
+
+// loadSamples returns the built-in samples for each component, along with those from the given sample files
+func (self *FilterTester) loadSamples(paths []string) (map[string][]*filterSample, error) {
+	if len(self.filterFiles) > 0 && len(self.components) != 1 {
+		return nil, errors.New("--filters-file requires a single --component")
+	}
+
+	result := map[string][]*filterSample{}
+	for _, component := range self.components {
+		if !stringz.Contains(sampleComponents, component) {
+			return nil, errors.Errorf("invalid component '%v'. Valid components: %v", component, strings.Join(sampleComponents, ", "))
+		}
+		samples, err := getBuiltInSamples(component)
+		if err != nil {
+			return nil, err
+		}
+		result[component] = samples
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		fileDef, err := parseSampleFile(path, data)
+		if err != nil {
+			return nil, err
+		}
+		result[fileDef.Component] = append(result[fileDef.Component], fileDef.Samples...)
+	}
+	return result, nil
+}
+
+func (self *FilterTester) run(cmd *cobra.Command, args []string) error {
+	samplesByComponent, err := self.loadSamples(args)
+	if err != nil {
+		return err
+	}
+	// failures from here on are test results rather than usage problems
+	cmd.SilenceUsage = true
+
+	total, failed := 0, 0
+	for _, component := range self.components {
+		parser, err := newComponentParser(component)
+		if err != nil {
+			return err
+		}
+		parser.filterFiles = self.filterFiles
+		if err = parser.validateFilters(); err != nil {
+			return err
+		}
+
+		samples := samplesByComponent[component]
+		failures := parser.testSamples(samples)
+
+		fmt.Printf("%v: %v samples, %v failed\n", component, len(samples), len(failures))
+		for _, failure := range failures {
+			fmt.Printf("    FAIL %v\n", failure)
+		}
+		if missing := parser.getFiltersWithoutSamples(samples); len(missing) > 0 {
+			fmt.Printf("    filters without samples: %v\n", strings.Join(missing, ", "))
+		}
+		total += len(samples)
+		failed += len(failures)
+	}
+
+	if failed > 0 {
+		return errors.Errorf("%v of %v samples failed", failed, total)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"testing"
+)
+
+// newTestParser returns a parser with the built-in filters for the component. The filters are set up directly, rather
+// than with validateFilters, so that filter files in the user's config directory don't affect the results
+func newTestParser(t *testing.T, component string) *JsonLogsParser {
+	t.Helper()
+	parser, err := newComponentParser(component)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = parser.setupVersions(); err != nil {
+		t.Fatal(err)
+	}
+	parser.include = AlwaysMatcher{}
+	return parser
+}
+
+// TestBuiltInFilterSamples checks every built-in filter against the sample corpus, so that changes to filters, or
+// to their order, which misclassify or shadow known log lines are caught
+func TestBuiltInFilterSamples(t *testing.T) {
+	for _, component := range sampleComponents {
+		t.Run(component, func(t *testing.T) {
+			parser := newTestParser(t, component)
+
+			samples, err := getBuiltInSamples(component)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, failure := range parser.testSamples(samples) {
+				t.Error(failure)
+			}
+
+			for _, id := range parser.getFiltersWithoutSamples(samples) {
+				t.Errorf("filter %v has no samples", id)
+			}
+		})
+	}
+}
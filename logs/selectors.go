@@ -0,0 +1,170 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/michaelquigley/pfxlog"
+	"github.com/pkg/errors"
+)
+
+// filterGroups are named sets of filter id patterns which can be selected using @<group>
+var filterGroups = map[string][]string{
+	"links":        {"LINK_*"},
+	"tls":          {"TLS_*", "CHANNEL_TLS_*"},
+	"channels":     {"CHANNEL_*"},
+	"ctrl-channel": {"CTRL_CH_*"},
+	"circuits":     {"CIRCUIT_*", "ROUTE_*", "REROUTE_*", "LATE_ROUTE_RESPONSE", "DIAL_FAILURE_NO_TERMINATOR", "IDLE_*", "FORWARD*"},
+	"xgress":       {"XG_*", "EGRESS_*"},
+	"terminators":  {"TERMINATOR_*"},
+	"tunnel":       {"TUNNEL_*"},
+	"lifecycle":    {"PROCESS_*", "SYSTEMD_*"},
+	"panics":       {"PANIC_*"},
+}
+
+// filterSelector decides which filters were selected by the include and exclude lists given on the command line.
+// Entries may be filter ids, glob patterns such as LINK_*, regular expressions between slashes such as /^TLS_/, or
+// groups such as @links. Include entries starting with ! are treated as excludes. If there are no includes, all
+// filters not excluded are selected
+type filterSelector struct {
+	selected map[string]bool
+}
+
+func newFilterSelector(include []string, exclude []string, filters []LogFilter) (*filterSelector, error) {
+	var includeMatchers []func(string) bool
+	var excludeMatchers []func(string) bool
+
+	add := func(selector string, excluded bool) error {
+		matchers, err := parseSelector(selector)
+		if err != nil {
+			return err
+		}
+		if !matchesAny(matchers, filters) {
+			// filters get renamed and removed, so scripts may still refer to ones which no longer exist
+			pfxlog.Logger().Warnf("'%v' doesn't match any filters", selector)
+		}
+		if excluded {
+			excludeMatchers = append(excludeMatchers, matchers...)
+		} else {
+			includeMatchers = append(includeMatchers, matchers...)
+		}
+		return nil
+	}
+
+	for _, selector := range include {
+		if negated, found := strings.CutPrefix(selector, "!"); found {
+			if err := add(negated, true); err != nil {
+				return nil, err
+			}
+		} else if err := add(selector, false); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, selector := range exclude {
+		if err := add(strings.TrimPrefix(selector, "!"), true); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &filterSelector{
+		selected: map[string]bool{},
+	}
+	for _, logFilter := range filters {
+		id := logFilter.Id()
+		included := len(includeMatchers) == 0 || anyMatch(includeMatchers, id)
+		result.selected[id] = included && !anyMatch(excludeMatchers, id)
+	}
+	return result, nil
+}
+
+// parseSelector turns a single selector into matchers. Groups expand to one matcher per pattern in the group
+func parseSelector(selector string) ([]func(string) bool, error) {
+	if group, found := strings.CutPrefix(selector, "@"); found {
+		patterns, found := filterGroups[group]
+		if !found {
+			return nil, errors.Errorf("unknown filter group '%v'. Valid groups: %v", group, strings.Join(getFilterGroupNames(), ", "))
+		}
+		var result []func(string) bool
+		for _, pattern := range patterns {
+			matcher, err := parseSelector(pattern)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, matcher...)
+		}
+		return result, nil
+	}
+
+	if len(selector) > 1 && strings.HasPrefix(selector, "/") && strings.HasSuffix(selector, "/") {
+		regex, err := regexp.Compile(selector[1 : len(selector)-1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid filter regex '%v'", selector)
+		}
+		return []func(string) bool{regex.MatchString}, nil
+	}
+
+	if strings.ContainsAny(selector, "*?[") {
+		if _, err := path.Match(selector, ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid filter pattern '%v'", selector)
+		}
+		return []func(string) bool{func(id string) bool {
+			match, _ := path.Match(selector, id)
+			return match
+		}}, nil
+	}
+
+	return []func(string) bool{func(id string) bool {
+		return id == selector
+	}}, nil
+}
+
+func anyMatch(matchers []func(string) bool, id string) bool {
+	for _, matcher := range matchers {
+		if matcher(id) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(matchers []func(string) bool, filters []LogFilter) bool {
+	for _, logFilter := range filters {
+		if anyMatch(matchers, logFilter.Id()) {
+			return true
+		}
+	}
+	return false
+}
+
+func getFilterGroupNames() []string {
+	var result []string
+	for name := range filterGroups {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// isSelected returns true if the given filter was selected
+func (self *filterSelector) isSelected(logFilter LogFilter) bool {
+	return self.selected[logFilter.Id()]
+}
@@ -0,0 +1,76 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/mgutz/ansi"
+	"github.com/pkg/errors"
+)
+
+// Severity indicates how much attention the entries matched by a filter deserve. Filters default to SeverityInfo
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarn
+	SeverityError
+	SeverityCritical
+)
+
+var severityNames = []string{"info", "warn", "error", "critical"}
+
+func (self Severity) String() string {
+	if self < 0 || int(self) >= len(severityNames) {
+		return "unknown"
+	}
+	return severityNames[self]
+}
+
+func ParseSeverity(s string) (Severity, error) {
+	for idx, name := range severityNames {
+		if strings.EqualFold(s, name) {
+			return Severity(idx), nil
+		}
+	}
+	return SeverityInfo, errors.Errorf("invalid severity '%v'. Valid severities: %v", s, strings.Join(severityNames, ", "))
+}
+
+var severityColors = map[Severity]string{
+	SeverityWarn:     ansi.ColorCode("yellow"),
+	SeverityError:    ansi.ColorCode("red"),
+	SeverityCritical: ansi.ColorCode("red+b"),
+}
+
+// colorize wraps s in the color for the severity, if color output is enabled
+func (self Severity) colorize(s string, color bool) string {
+	if code, found := severityColors[self]; found && color {
+		return code + s + ansi.Reset
+	}
+	return s
+}
+
+// useColor returns true if stdout is a terminal and the NO_COLOR convention isn't being used to turn color off
+func useColor() bool {
+	if _, found := os.LookupEnv("NO_COLOR"); found {
+		return false
+	}
+	return isatty.IsTerminal(os.Stdout.Fd())
+}
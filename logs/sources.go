@@ -0,0 +1,535 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// entryTimeFormat is used to show entry times from several sources, with enough precision to order them
+const entryTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// logSource is the logs of a single node, labelled so that entries from different nodes can be told apart
+type logSource struct {
+	label     string
+	component string
+	inputs    []string
+}
+
+// parseLogSources parses <label>=<input> specs for the given component. Inputs may be files, directories or glob
+// patterns, and repeating a label adds inputs to that source. Without a label, the file name up to the first dot is
+// used. A spec only has a label if the part before the first = isn't a path, so that paths containing = can be given
+// without one
+func parseLogSources(component string, specs []string) ([]*logSource, error) {
+	var result []*logSource
+	byLabel := map[string]*logSource{}
+	for _, spec := range specs {
+		label, input, found := strings.Cut(spec, "=")
+		if !found || strings.ContainsAny(label, "/"+string(filepath.Separator)) {
+			input = spec
+			label, _, _ = strings.Cut(filepath.Base(input), ".")
+		}
+		if label == "" || input == "" {
+			return nil, errors.Errorf("invalid log source '%v', expected <label>=<file>", spec)
+		}
+
+		source, found := byLabel[label]
+		if !found {
+			source = &logSource{label: label, component: component}
+			byLabel[label] = source
+			result = append(result, source)
+		}
+		source.inputs = append(source.inputs, input)
+	}
+	return result, nil
+}
+
+// multiSourceOptions holds the parsing options shared by the commands which read the logs of several nodes at once
+type multiSourceOptions struct {
+	controllers []string
+	routers     []string
+	beforeTime  string
+	afterTime   string
+	inputFormat string
+	timezone    string
+	where       string
+	formatter   string
+}
+
+func (self *multiSourceOptions) addArgs(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVarP(&self.controllers, "controller", "c", nil, "Controller logs, as <label>=<file>. May be repeated")
+	self.addRouterArgs(cmd)
+}
+
+// addRouterArgs adds the arguments for commands which only use router logs
+func (self *multiSourceOptions) addRouterArgs(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVarP(&self.routers, "router", "r", nil, "Router logs, as <label>=<file>. May be repeated")
+	cmd.Flags().StringVarP(&self.beforeTime, "before", "B", "", "Process only messages before this timestamp")
+	cmd.Flags().StringVarP(&self.afterTime, "after", "A", "", "Process only messages after this timestamp")
+	cmd.Flags().StringVar(&self.inputFormat, "input-format", InputFormatAuto, fmt.Sprintf("Specify input format: [%v]", strings.Join(InputFormats, "|")))
+	cmd.Flags().StringVar(&self.timezone, "timezone", "UTC", "Time zone of log collector timestamps which don't include one, such as journald's, e.g. Local or America/New_York")
+	cmd.Flags().StringVarP(&self.where, "where", "w", "", `Only process entries matching this field query, ex: 'msg ~ "route" && level == error'`)
+	cmd.Flags().StringVarP(&self.formatter, "output", "o", "text", "Specify output format: [text|json]")
+}
+
+// getSources returns the sources given with --controller and --router, along with any given as arguments, whose
+// component is detected from their contents
+func (self *multiSourceOptions) getSources(args []string) ([]*logSource, error) {
+	controllers, err := parseLogSources("controller", self.controllers)
+	if err != nil {
+		return nil, err
+	}
+	routers, err := parseLogSources("router", self.routers)
+	if err != nil {
+		return nil, err
+	}
+	detected, err := parseLogSources("", args)
+	if err != nil {
+		return nil, err
+	}
+	if len(detected) > 0 {
+		if err = validateInputFormat(self.inputFormat); err != nil {
+			return nil, err
+		}
+	}
+	for _, source := range detected {
+		if source.component, err = detectComponent(source, self.inputFormat); err != nil {
+			return nil, err
+		}
+	}
+
+	result := append(append(controllers, routers...), detected...)
+	if len(result) == 0 {
+		return nil, errors.New("no logs given")
+	}
+
+	labels := map[string]*logSource{}
+	for _, source := range result {
+		if other, found := labels[source.label]; found {
+			return nil, errors.Errorf("label %v used for both %v and %v logs", source.label, other.component, source.component)
+		}
+		labels[source.label] = source
+	}
+	return result, nil
+}
+
+// componentSourceRegex picks out the component from the source file paths or functions logged by ziti, such as
+// github.com/openziti/fabric@v0.22.0/controller/network/network.go
+var componentSourceRegex = regexp.MustCompile(`/(controller|router)/`)
+
+// detectComponent works out whether the source holds controller or router logs from the source files, or the logged
+// functions, of its first entries, falling back to the label
+func detectComponent(source *logSource, inputFormat string) (string, error) {
+	paths, err := expandInputs(source.inputs)
+	if err != nil {
+		return "", err
+	}
+
+	votes := map[string]int{}
+	options := &InputOptions{Format: inputFormat, Location: time.UTC}
+	for _, path := range paths {
+		if path == StdinInput {
+			continue
+		}
+		err = scanJsonLines([]string{path}, options, func(ctx *JsonParseContext) error {
+			if ctx.eof || ctx.lineNumber > maxLinesForFirstTimestamp {
+				return errStopScan
+			}
+			if ctx.entry != nil {
+				source := ctx.GetString("file")
+				if source == "" {
+					source = ctx.GetString("func")
+				}
+				if match := componentSourceRegex.FindStringSubmatch(source); match != nil {
+					votes[match[1]]++
+				}
+			}
+			return nil
+		})
+		if err != nil && errors.Cause(err) != errStopScan {
+			return "", err
+		}
+	}
+
+	switch {
+	case votes["controller"] > votes["router"]:
+		return "controller", nil
+	case votes["router"] > votes["controller"]:
+		return "router", nil
+	case strings.HasPrefix(source.label, "ctrl") || strings.HasPrefix(source.label, "controller"):
+		return "controller", nil
+	}
+	return "", errors.Errorf("unable to tell if %v holds controller or router logs, use --controller or --router", source.label)
+}
+
+// newParser returns a parser for the source's component, configured with the shared options and validated
+func (self *multiSourceOptions) newParser(source *logSource) (*JsonLogsParser, error) {
+	parser, err := newComponentParser(source.component)
+	if err != nil {
+		return nil, err
+	}
+	parser.beforeTime = self.beforeTime
+	parser.afterTime = self.afterTime
+	parser.inputFormat = self.inputFormat
+	parser.timezone = self.timezone
+	parser.where = self.where
+	parser.formatter = self.formatter
+	if err = parser.validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid options for %v", source.label)
+	}
+	return parser, nil
+}
+
+// startStreams creates a parser for each source and starts reading them, passing the entries accepted by the predicate
+// to the streams. The column widths for showing the entries as text are worked out from the sources and their filters
+func (self *multiSourceOptions) startStreams(cmd *cobra.Command, sources []*logSource, accept func(ctx *JsonParseContext) bool) ([]*sourceStream, *entryColumns, error) {
+	var parsers []*JsonLogsParser
+	columns := &entryColumns{node: len("NODE"), filter: len("FILTER"), level: len("warning")}
+	for _, source := range sources {
+		parser, err := self.newParser(source)
+		if err != nil {
+			return nil, nil, err
+		}
+		cmd.SilenceUsage = true
+		parsers = append(parsers, parser)
+
+		columns.node = max(columns.node, len(source.label))
+		for _, logFilter := range parser.filters {
+			columns.filter = max(columns.filter, len(logFilter.Id()))
+		}
+	}
+
+	var streams []*sourceStream
+	for idx, source := range sources {
+		streams = append(streams, startSourceStream(parsers[idx], source, accept))
+	}
+	return streams, columns, nil
+}
+
+// sourceEntry is a log entry along with the node it was logged by and the filter which matched it, if any
+type sourceEntry struct {
+	Time      time.Time         `json:"time"`
+	Offset    string            `json:"offset,omitempty"`
+	Node      string            `json:"node"`
+	Component string            `json:"component"`
+	Filter    string            `json:"filter,omitempty"`
+	Severity  string            `json:"severity,omitempty"`
+	Level     string            `json:"level,omitempty"`
+	Msg       string            `json:"msg"`
+	Error     string            `json:"error,omitempty"`
+	File      string            `json:"file,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	line      string
+	severity  *Severity
+}
+
+// SourceEntryHandler passes the entries from one source which are accepted by the predicate, whether or not a filter
+// matches them, to the emit callback
+type SourceEntryHandler struct {
+	source *logSource
+	accept func(ctx *JsonParseContext) bool
+	emit   func(entry *sourceEntry)
+}
+
+func NewSourceEntryHandler(source *logSource, accept func(ctx *JsonParseContext) bool, emit func(entry *sourceEntry)) *SourceEntryHandler {
+	return &SourceEntryHandler{
+		source: source,
+		accept: accept,
+		emit:   emit,
+	}
+}
+
+func (self *SourceEntryHandler) HandleNewLine(*JsonParseContext) error {
+	return nil
+}
+
+func (self *SourceEntryHandler) HandleMatch(ctx *JsonParseContext, logFilter LogFilter) error {
+	self.add(ctx, logFilter)
+	return nil
+}
+
+func (self *SourceEntryHandler) HandleUnmatched(ctx *JsonParseContext) error {
+	self.add(ctx, nil)
+	return nil
+}
+
+func (self *SourceEntryHandler) HandleEnd(*JsonParseContext) {}
+
+func (self *SourceEntryHandler) add(ctx *JsonParseContext, logFilter LogFilter) {
+	if self.accept != nil && !self.accept(ctx) {
+		return
+	}
+
+	entry := &sourceEntry{
+		Time:      getEntryTime(ctx),
+		Node:      self.source.label,
+		Component: self.source.component,
+		Fields:    ctx.extracted,
+		line:      strings.TrimRight(ctx.line, "\n"),
+	}
+	if logFilter != nil {
+		severity := logFilter.Severity()
+		entry.Filter = logFilter.Id()
+		entry.Severity = severity.String()
+		entry.severity = &severity
+	}
+
+	switch {
+	case ctx.entry != nil:
+		entry.Level = ctx.GetString("level")
+		entry.Msg = ctx.GetString("msg")
+		entry.Error = ctx.GetString("error")
+		entry.File = getSourceFile(ctx.GetString("file"))
+	case ctx.systemd != nil:
+		entry.Msg = *ctx.systemd
+	default:
+		entry.Msg, _, _ = strings.Cut(strings.TrimSpace(ctx.line), "\n")
+	}
+	self.emit(entry)
+}
+
+// sourceStreamBuffer is the number of entries a source can read ahead of the merge
+const sourceStreamBuffer = 256
+
+// sourceStream reads the entries of one source in the background, so that the entries of several sources can be
+// merged as they're read, rather than after reading all of them
+type sourceStream struct {
+	source  *logSource
+	entries chan *sourceEntry
+	err     error
+}
+
+// startSourceStream starts scanning the source with the parser, passing the entries accepted by the predicate to the
+// stream. The stream's channel is closed once the source has been read, after err is set
+func startSourceStream(parser *JsonLogsParser, source *logSource, accept func(ctx *JsonParseContext) bool) *sourceStream {
+	stream := &sourceStream{
+		source:  source,
+		entries: make(chan *sourceEntry, sourceStreamBuffer),
+	}
+	parser.handler = NewSourceEntryHandler(source, accept, func(entry *sourceEntry) {
+		stream.entries <- entry
+	})
+	go func() {
+		defer close(stream.entries)
+		stream.err = parser.scan(source.inputs)
+	}()
+	return stream
+}
+
+// drain discards the rest of the stream, so that its scan can finish
+func (self *sourceStream) drain() {
+	go func() {
+		for range self.entries {
+		}
+	}()
+}
+
+// pendingEntry is the next entry from one of the streams being merged
+type pendingEntry struct {
+	entry  *sourceEntry
+	stream int
+}
+
+// pendingEntryHeap orders the next entry of each stream by time. Entries with the same time are taken in stream
+// order, and each stream is in log order, so entries are in the same order a stable sort would give
+type pendingEntryHeap []*pendingEntry
+
+func (self pendingEntryHeap) Len() int {
+	return len(self)
+}
+
+func (self pendingEntryHeap) Less(i, j int) bool {
+	if !self[i].entry.Time.Equal(self[j].entry.Time) {
+		return self[i].entry.Time.Before(self[j].entry.Time)
+	}
+	return self[i].stream < self[j].stream
+}
+
+func (self pendingEntryHeap) Swap(i, j int) {
+	self[i], self[j] = self[j], self[i]
+}
+
+func (self *pendingEntryHeap) Push(x interface{}) {
+	*self = append(*self, x.(*pendingEntry))
+}
+
+func (self *pendingEntryHeap) Pop() interface{} {
+	old := *self
+	result := old[len(old)-1]
+	*self = old[:len(old)-1]
+	return result
+}
+
+// mergeSourceStreams passes the entries of all the streams to the callback in time order. Each source is already in
+// time order, so only the next entry of each is held, rather than every entry of every source
+func mergeSourceStreams(streams []*sourceStream, callback func(entry *sourceEntry) error) error {
+	pending := &pendingEntryHeap{}
+	var err error
+
+	next := func(idx int) {
+		entry, ok := <-streams[idx].entries
+		if ok {
+			heap.Push(pending, &pendingEntry{entry: entry, stream: idx})
+		} else if streams[idx].err != nil && err == nil {
+			err = streams[idx].err
+		}
+	}
+
+	for idx := range streams {
+		next(idx)
+	}
+	for err == nil && pending.Len() > 0 {
+		current := heap.Pop(pending).(*pendingEntry)
+		err = callback(current.entry)
+		next(current.stream)
+	}
+
+	if err != nil {
+		for _, stream := range streams {
+			stream.drain()
+		}
+	}
+	return err
+}
+
+// getMessage returns the message, along with the error if one was logged
+func (self *sourceEntry) getMessage() string {
+	if self.Error != "" {
+		return self.Msg + ": " + self.Error
+	}
+	return self.Msg
+}
+
+// getEntryTime returns the time of the current entry, falling back to the last time seen for lines without one, such
+// as panic output
+func getEntryTime(ctx *JsonParseContext) time.Time {
+	if t, err := ctx.GetTime(); err == nil {
+		return t
+	}
+	return ctx.lastTime
+}
+
+// entryColumns holds the widths of the text columns for entries from several sources. They're worked out up front,
+// since entries are output as they're merged
+type entryColumns struct {
+	node   int
+	filter int
+	level  int
+}
+
+// offsetWidth is the width of the offset column, which fits offsets of up to an hour with millisecond precision
+const offsetWidth = len("+59m59.999s")
+
+// newTextOutput returns an output which writes each entry as a table row, with the table header before the first
+// row. If offsets is true, the entry offsets are shown after the times
+func (self *entryColumns) newTextOutput(offsets bool) func(entry *sourceEntry) error {
+	headers := []interface{}{"TIME", "NODE", "FILTER", "LEVEL", "MESSAGE"}
+	widths := []int{len(entryTimeFormat), self.node, self.filter, self.level}
+	if offsets {
+		headers = append([]interface{}{"TIME", "OFFSET"}, headers[1:]...)
+		widths = append([]int{len(entryTimeFormat), offsetWidth}, widths[1:]...)
+	}
+	format := ""
+	for _, width := range widths {
+		format += fmt.Sprintf("%%-%dv  ", width)
+	}
+	format += "%v\n"
+
+	header := false
+	return func(entry *sourceEntry) error {
+		if !header {
+			header = true
+			if _, err := fmt.Printf(format, headers...); err != nil {
+				return err
+			}
+		}
+
+		filterId := entry.Filter
+		if filterId == "" {
+			filterId = "-"
+		}
+		values := []interface{}{entry.Time.Format(entryTimeFormat), entry.Node, filterId, entry.Level, entry.getMessage()}
+		if offsets {
+			values = append([]interface{}{values[0], "+" + entry.Offset}, values[1:]...)
+		}
+		_, err := fmt.Printf(format, values...)
+		return err
+	}
+}
+
+// jsonEntriesOutput writes a json object whose entries array is written as entries are merged. Fields which are only
+// known once all the entries have been seen, such as counts, are written after the array
+type jsonEntriesOutput struct {
+	separator string
+}
+
+// startJsonEntries writes the opening of the object, with the given fields before the entries array
+func startJsonEntries(leading map[string]interface{}) (*jsonEntriesOutput, error) {
+	var fields []string
+	for _, key := range sortedKeys(leading) {
+		j, err := json.Marshal(leading[key])
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, fmt.Sprintf("%q:%s,", key, j))
+	}
+	_, err := fmt.Printf(`{%v"entries":[`, strings.Join(fields, ""))
+	return &jsonEntriesOutput{}, err
+}
+
+func (self *jsonEntriesOutput) write(entry *sourceEntry) error {
+	j, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Printf("%v%s", self.separator, j)
+	self.separator = ","
+	return err
+}
+
+// finish closes the entries array and writes the given fields after it
+func (self *jsonEntriesOutput) finish(trailing map[string]interface{}) error {
+	var fields []string
+	for _, key := range sortedKeys(trailing) {
+		j, err := json.Marshal(trailing[key])
+		if err != nil {
+			return err
+		}
+		fields = append(fields, fmt.Sprintf(",%q:%s", key, j))
+	}
+	_, err := fmt.Printf("]%v}\n", strings.Join(fields, ""))
+	return err
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var result []string
+	for key := range m {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,96 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestStream returns a closed stream holding entries for the node at the given offsets, in seconds
+func newTestStream(node string, offsets ...int) *sourceStream {
+	stream := &sourceStream{
+		source:  &logSource{label: node},
+		entries: make(chan *sourceEntry, len(offsets)),
+	}
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	for idx, offset := range offsets {
+		stream.entries <- &sourceEntry{
+			Time: start.Add(time.Duration(offset) * time.Second),
+			Node: node,
+			Msg:  string(rune('a' + idx)),
+		}
+	}
+	close(stream.entries)
+	return stream
+}
+
+// TestMergeSourceStreams checks that entries from several streams are merged by time, with entries at the same time
+// kept in stream order and then log order
+func TestMergeSourceStreams(t *testing.T) {
+	streams := []*sourceStream{
+		newTestStream("ctrl", 1, 2, 2, 5),
+		newTestStream("r1", 0, 2, 6),
+		newTestStream("r2"),
+		newTestStream("r3", 3),
+	}
+
+	var merged []string
+	err := mergeSourceStreams(streams, func(entry *sourceEntry) error {
+		merged = append(merged, entry.Node+":"+entry.Msg)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "r1:a ctrl:a ctrl:b ctrl:c r1:b r3:a ctrl:d r1:c"
+	if result := strings.Join(merged, " "); result != expected {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestParseLogSources(t *testing.T) {
+	tests := []struct {
+		spec   string
+		label  string
+		inputs string
+	}{
+		{"r1=router1.log", "r1", "router1.log"},
+		{"router1.log", "router1", "router1.log"},
+		{"logs/router1.log", "router1", "logs/router1.log"},
+		{"logs/run=2/router1.log", "router1", "logs/run=2/router1.log"},
+		{"r1=logs/run=2/router1.log", "r1", "logs/run=2/router1.log"},
+		{"r1=logs/*.log", "r1", "logs/*.log"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			sources, err := parseLogSources("router", []string{test.spec})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sources) != 1 {
+				t.Fatalf("expected 1 source, got %v", len(sources))
+			}
+			if source := sources[0]; source.label != test.label || strings.Join(source.inputs, ",") != test.inputs {
+				t.Errorf("expected %v with %v, got %v with %v", test.label, test.inputs, source.label, source.inputs)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// templateMasks replace the variable parts of messages with placeholders. They're applied in order, so more specific
+// patterns, such as uuids and addresses, are masked before the generic id and number patterns
+var templateMasks = []struct {
+	regex       *regexp.Regexp
+	placeholder string
+}{
+	{regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`), "<uuid>"},
+	{regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b`), "<ip>"},
+	{regexp.MustCompile(`\[[0-9a-fA-F:]*:[0-9a-fA-F:]*\](?::\d+)?`), "<ip>"},
+	{regexp.MustCompile(`\b\d+(?:\.\d+)?(?:ns|us|µs|ms|s|m|h)(?:\d+(?:\.\d+)?(?:ns|us|µs|ms|s|m|h))*\b`), "<duration>"},
+	{regexp.MustCompile(`\b\d+(?:\.\d+)?\b`), "<n>"},
+	{regexp.MustCompile(`\b[\w\-.]*\d[\w\-.]*\b`), "<id>"},
+}
+
+var templatePlaceholderRegex = regexp.MustCompile(`<(?:uuid|ip|duration|n|id)>`)
+var templateWordRegex = regexp.MustCompile(`[a-zA-Z]+`)
+var fileLineRegex = regexp.MustCompile(`:\d+$`)
+
+// toTemplate masks ids, addresses, numbers and durations in the message, so that messages which differ only in
+// those values map to the same template
+func toTemplate(msg string) string {
+	result := msg
+	for _, mask := range templateMasks {
+		result = mask.regex.ReplaceAllString(result, mask.placeholder)
+	}
+	return result
+}
+
+// templateToRegex returns a regex matching the messages which produce the given template
+func templateToRegex(template string) string {
+	result := &strings.Builder{}
+	result.WriteString("^")
+	last := 0
+	for _, loc := range templatePlaceholderRegex.FindAllStringIndex(template, -1) {
+		result.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		result.WriteString(`\S+`)
+		last = loc[1]
+	}
+	result.WriteString(regexp.QuoteMeta(template[last:]))
+	result.WriteString("$")
+	return result.String()
+}
+
+// getSourceFile returns the last two elements of the source path, without line number, which is how the built-in
+// filters identify source files
+func getSourceFile(file string) string {
+	file = fileLineRegex.ReplaceAllString(file, "")
+	parts := strings.Split(file, "/")
+	if len(parts) > 2 {
+		parts = parts[len(parts)-2:]
+	}
+	return strings.Join(parts, "/")
+}
+
+// unmatchedCluster is a group of unmatched entries with the same message template and source
+type unmatchedCluster struct {
+	Template string     `json:"template"`
+	File     string     `json:"file,omitempty"`
+	Func     string     `json:"func,omitempty"`
+	Level    string     `json:"level,omitempty"`
+	Count    int        `json:"count"`
+	Example  string     `json:"example"`
+	Filter   *filterDef `json:"filter"`
+}
+
+type unmatchedClusterKey struct {
+	template string
+	file     string
+	function string
+}
+
+// SuggestHandler clusters unmatched entries into message templates and suggests filter definitions for the most
+// frequent ones
+type SuggestHandler struct {
+	clusters  map[unmatchedClusterKey]*unmatchedCluster
+	limit     int
+	formatter string
+}
+
+func NewSuggestHandler(limit int, formatter string) *SuggestHandler {
+	return &SuggestHandler{
+		clusters:  map[unmatchedClusterKey]*unmatchedCluster{},
+		limit:     limit,
+		formatter: formatter,
+	}
+}
+
+func (self *SuggestHandler) HandleNewLine(*JsonParseContext) error {
+	return nil
+}
+
+func (self *SuggestHandler) HandleMatch(*JsonParseContext, LogFilter) error {
+	return nil
+}
+
+func (self *SuggestHandler) HandleUnmatched(ctx *JsonParseContext) error {
+	if ctx.entry == nil {
+		return nil
+	}
+	msg := ctx.GetString("msg")
+	if msg == "" {
+		return nil
+	}
+
+	key := unmatchedClusterKey{
+		template: toTemplate(msg),
+		file:     getSourceFile(ctx.GetString("file")),
+		function: ctx.GetString("func"),
+	}
+	cluster, found := self.clusters[key]
+	if !found {
+		cluster = &unmatchedCluster{
+			Template: key.template,
+			File:     key.file,
+			Func:     key.function,
+			Level:    ctx.GetString("level"),
+			Example:  msg,
+		}
+		self.clusters[key] = cluster
+	}
+	cluster.Count++
+	return nil
+}
+
+func (self *SuggestHandler) HandleEnd(*JsonParseContext) {
+	clusters := self.getRankedClusters()
+	ids := map[string]int{}
+	for _, cluster := range clusters {
+		cluster.Filter = cluster.toFilterDef(ids)
+	}
+
+	if self.formatter == "json" {
+		self.dumpJson(clusters)
+	} else {
+		self.dumpText(clusters)
+	}
+}
+
+// getRankedClusters returns the clusters with the most entries first, up to the limit
+func (self *SuggestHandler) getRankedClusters() []*unmatchedCluster {
+	var result []*unmatchedCluster
+	for _, cluster := range self.clusters {
+		result = append(result, cluster)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		if result[i].File != result[j].File {
+			return result[i].File < result[j].File
+		}
+		return result[i].Template < result[j].Template
+	})
+	if self.limit > 0 && len(result) > self.limit {
+		result = result[:self.limit]
+	}
+	return result
+}
+
+// toFilterDef builds a filter definition matching the entries in the cluster. The id is made from the first words of
+// the template, and is made unique using the given set of ids already suggested
+func (self *unmatchedCluster) toFilterDef(ids map[string]int) *filterDef {
+	var words []string
+	for _, word := range templateWordRegex.FindAllString(templatePlaceholderRegex.ReplaceAllString(self.Template, " "), -1) {
+		words = append(words, strings.ToUpper(word))
+		if len(words) == 4 {
+			break
+		}
+	}
+	id := strings.Join(words, "_")
+	if id == "" {
+		id = "UNMATCHED"
+	}
+	ids[id]++
+	if ids[id] > 1 {
+		id = fmt.Sprintf("%v_%v", id, ids[id])
+	}
+
+	result := &filterDef{
+		Id:   id,
+		Desc: self.Template,
+	}
+
+	switch strings.ToLower(self.Level) {
+	case "warning", "warn":
+		result.Severity = SeverityWarn.String()
+	case "error":
+		result.Severity = SeverityError.String()
+	case "fatal", "panic":
+		result.Severity = SeverityCritical.String()
+	}
+
+	msgMatcher := &matcherDef{Field: "msg"}
+	if templatePlaceholderRegex.MatchString(self.Template) {
+		regex := templateToRegex(self.Template)
+		msgMatcher.Matches = &regex
+	} else {
+		msgMatcher.Equals = &self.Template
+	}
+
+	result.Match = msgMatcher
+	if self.File != "" {
+		file := self.File
+		result.Match = &matcherDef{
+			And: []*matcherDef{msgMatcher, {Field: "file", Contains: &file}},
+		}
+	}
+	return result
+}
+
+func (self *SuggestHandler) dumpText(clusters []*unmatchedCluster) {
+	if len(clusters) == 0 {
+		fmt.Println("no unmatched entries found")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "COUNT\tLEVEL\tFILE\tFUNC\tTEMPLATE")
+	for _, cluster := range clusters {
+		_, _ = fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", cluster.Count, cluster.Level, cluster.File, cluster.Func, cluster.Template)
+	}
+	_ = w.Flush()
+
+	fileDef := &filterFileDef{}
+	for _, cluster := range clusters {
+		fileDef.Filters = append(fileDef.Filters, cluster.Filter)
+	}
+	fmt.Printf("\n# suggested filters, review the ids, descriptions and matches before use\n")
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(fileDef); err != nil {
+		panic(err)
+	}
+	_ = encoder.Close()
+}
+
+func (self *SuggestHandler) dumpJson(clusters []*unmatchedCluster) {
+	if clusters == nil {
+		clusters = []*unmatchedCluster{}
+	}
+
+	j, err := json.Marshal(map[string]interface{}{
+		"clusters": clusters,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s\n", string(j))
+}
+
+func (self *JsonLogsParser) addSuggestArgs(cmd *cobra.Command) {
+	self.addCommonArgs(cmd)
+	self.addWhereArgs(cmd)
+	cmd.Flags().IntVarP(&self.suggestLimit, "top", "n", 20, "Number of the most frequent unmatched message templates to suggest filters for, or 0 for all")
+	cmd.Flags().StringVarP(&self.formatter, "output", "o", "text", "Specify output format: [text|json]")
+}
+
+func (self *JsonLogsParser) suggestFilters(_ *cobra.Command, args []string) error {
+	if err := self.validate(); err != nil {
+		return err
+	}
+	if self.suggestLimit < 0 {
+		return errors.Errorf("invalid --top %v, must be 0 or more", self.suggestLimit)
+	}
+
+	self.handler = NewSuggestHandler(self.suggestLimit, self.formatter)
+
+	return self.scan(args)
+}
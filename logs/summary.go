@@ -19,7 +19,6 @@ package logs
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/openziti/foundation/v2/stringz"
 	"github.com/pkg/errors"
 	"sort"
 	"time"
@@ -28,40 +27,176 @@ import (
 type LogSummaryHandler struct {
 	bucketSize                  time.Duration
 	currentBucket               time.Time
+	bucketFlushed               bool
+	bucketLate                  bool
+	lastEntryTime               time.Time
+	lastEntryReceived           time.Time
 	bucketMatches               map[LogFilter]int
 	unmatched                   int
 	maxUnmatchedLoggedPerBucket int
-	ignore                      []string
+	selector                    *filterSelector
 	formatter                   string
+	minSeverity                 *Severity
+	color                       bool
+	overlaps                    map[filterPair]int
+	groupBy                     string
+	groupLimit                  int
+	bucketGroups                map[LogFilter]map[string]int
+}
+
+// groupValueCount is the number of matches in a bucket with the given value for the --group-by field
+type groupValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// filterPair identifies two filters which matched the same entry, with the ids in sorted order
+type filterPair struct {
+	first  string
+	second string
 }
 
 func (self *LogSummaryHandler) HandleNewLine(ctx *JsonParseContext) error {
-	if ctx.entry != nil {
-		s := ctx.GetString("time")
-		t, err := time.Parse(time.RFC3339, s)
-		if err != nil {
+	// lines are bucketed by the entry time, or by the log collector timestamp for lines which aren't json entries,
+	// such as systemd messages in journald output
+	t, err := ctx.GetTime()
+	if err != nil {
+		if s := ctx.GetString("time"); ctx.entry != nil && s != "" {
 			return errors.Errorf("time is in an unexpected format: %v", s)
 		}
-		interval := t.Truncate(self.bucketSize)
-		if interval != self.currentBucket {
-			if !self.currentBucket.IsZero() {
-				self.dumpBucket()
-			}
-			self.currentBucket = interval
-			self.bucketMatches = map[LogFilter]int{}
-			self.unmatched = 0
+		// lines without any timestamp, such as panic output or text entries with relative timestamps, are counted in
+		// the current bucket
+		return nil
+	}
+
+	self.lastEntryTime = t
+	self.lastEntryReceived = time.Now()
+
+	interval := t.Truncate(self.bucketSize)
+	if interval != self.currentBucket {
+		if !self.currentBucket.IsZero() && !self.bucketFlushed {
+			self.dumpBucket()
 		}
+		self.currentBucket = interval
+		self.bucketLate = false
+		self.resetBucket()
+	} else if self.bucketFlushed {
+		// a late entry for an interval which was already output. Only the late entries are counted and output, marked
+		// as late, so the counts for the interval add up instead of overlapping
+		self.bucketLate = true
+		self.resetBucket()
 	}
 	return nil
 }
 
+func (self *LogSummaryHandler) resetBucket() {
+	self.bucketFlushed = false
+	self.bucketMatches = map[LogFilter]int{}
+	self.bucketGroups = map[LogFilter]map[string]int{}
+	self.unmatched = 0
+}
+
 func (self *LogSummaryHandler) HandleEnd(*JsonParseContext) {
+	if !self.bucketFlushed {
+		self.dumpBucket()
+	}
+	self.dumpOverlaps()
+}
+
+// HandleIdle outputs the current bucket once its interval has closed, so that following logs doesn't have to wait
+// for the next entry to see it. Logs may lag behind the clock, or be replayed history, so the interval is closed once
+// the log time would have passed it, going by how long it's been since the last entry. The bucket stays current, so
+// more entries in the same interval don't start it over
+func (self *LogSummaryHandler) HandleIdle(now time.Time) error {
+	if self.currentBucket.IsZero() || self.bucketFlushed {
+		return nil
+	}
+	logNow := self.lastEntryTime.Add(now.Sub(self.lastEntryReceived))
+	if logNow.Before(self.currentBucket.Add(self.bucketSize)) {
+		return nil
+	}
 	self.dumpBucket()
+	self.bucketFlushed = true
+	return nil
 }
 
 func (self *LogSummaryHandler) HandleMatch(ctx *JsonParseContext, logFilter LogFilter) error {
-	current := self.bucketMatches[logFilter]
-	self.bucketMatches[logFilter] = current + 1
+	self.countMatch(ctx, logFilter)
+	return nil
+}
+
+// countMatch counts the match for the filter, and for the entry's value of the --group-by field if one was given
+func (self *LogSummaryHandler) countMatch(ctx *JsonParseContext, logFilter LogFilter) {
+	self.bucketMatches[logFilter]++
+	if self.groupBy == "" {
+		return
+	}
+
+	if self.bucketGroups == nil {
+		self.bucketGroups = map[LogFilter]map[string]int{}
+	}
+	groups, found := self.bucketGroups[logFilter]
+	if !found {
+		groups = map[string]int{}
+		self.bucketGroups[logFilter] = groups
+	}
+	groups[self.getGroupValue(ctx)]++
+}
+
+// getGroupValue returns the entry's value for the --group-by field, which may be a field extracted by the filter
+func (self *LogSummaryHandler) getGroupValue(ctx *JsonParseContext) string {
+	if ctx.entry != nil {
+		if v := ctx.GetString(self.groupBy); v != "" {
+			return v
+		}
+	}
+	return "(none)"
+}
+
+// getTopGroups returns the most frequent values of the --group-by field for the filter in the current bucket, up to
+// the limit, along with the total count of the values left out
+func (self *LogSummaryHandler) getTopGroups(logFilter LogFilter) ([]*groupValueCount, int) {
+	var result []*groupValueCount
+	for value, count := range self.bucketGroups[logFilter] {
+		result = append(result, &groupValueCount{Value: value, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Value < result[j].Value
+	})
+
+	others := 0
+	if self.groupLimit > 0 && len(result) > self.groupLimit {
+		for _, v := range result[self.groupLimit:] {
+			others += v.Count
+		}
+		result = result[:self.groupLimit]
+	}
+	return result, others
+}
+
+// HandleMatches counts the entry for each matching filter, and records which of the reported filters matched it
+// together, so that overlapping filters can be reported at the end
+func (self *LogSummaryHandler) HandleMatches(ctx *JsonParseContext, logFilters []LogFilter) error {
+	var ids []string
+	for _, logFilter := range logFilters {
+		self.countMatch(ctx, logFilter)
+		if self.isReported(logFilter) {
+			ids = append(ids, logFilter.Id())
+		}
+	}
+
+	if len(ids) > 1 && self.overlaps == nil {
+		self.overlaps = map[filterPair]int{}
+	}
+	sort.Strings(ids)
+	for i := range ids {
+		for _, other := range ids[i+1:] {
+			self.overlaps[filterPair{first: ids[i], second: other}]++
+		}
+	}
 	return nil
 }
 
@@ -86,10 +221,18 @@ func (self *LogSummaryHandler) dumpBucket() {
 
 }
 
+// isReported returns true if the filter was selected and meets the minimum severity
+func (self *LogSummaryHandler) isReported(logFilter LogFilter) bool {
+	if !self.selector.isSelected(logFilter) {
+		return false
+	}
+	return self.minSeverity == nil || logFilter.Severity() >= *self.minSeverity
+}
+
 func (self *LogSummaryHandler) dumpBucketText() {
 	var filters []LogFilter
 	for k := range self.bucketMatches {
-		if !stringz.Contains(self.ignore, k.Id()) {
+		if self.isReported(k) {
 			filters = append(filters, k)
 		}
 	}
@@ -99,9 +242,23 @@ func (self *LogSummaryHandler) dumpBucketText() {
 	if len(filters) == 0 && self.unmatched == 0 {
 		return
 	}
-	fmt.Printf("%v\n---------------------------------------------------\n", self.currentBucket.Format(time.RFC3339))
+	header := self.currentBucket.Format(time.RFC3339)
+	if self.bucketLate {
+		header += " (late entries)"
+	}
+	fmt.Printf("%v\n---------------------------------------------------\n", header)
 	for _, filter := range filters {
-		fmt.Printf("    %v: %0000v\n", filter.Id(), self.bucketMatches[filter])
+		line := fmt.Sprintf("    %v: %0000v", filter.Id(), self.bucketMatches[filter])
+		fmt.Println(filter.Severity().colorize(line, self.color))
+		if self.groupBy != "" {
+			groups, others := self.getTopGroups(filter)
+			for _, group := range groups {
+				fmt.Printf("        %v: %v\n", group.Value, group.Count)
+			}
+			if others > 0 {
+				fmt.Printf("        (other values): %v\n", others)
+			}
+		}
 	}
 	if self.unmatched > 0 {
 		fmt.Printf("    unmatched: %0000v\n", self.unmatched)
@@ -112,7 +269,7 @@ func (self *LogSummaryHandler) dumpBucketText() {
 func (self *LogSummaryHandler) dumpBucketJson() {
 	var filters []LogFilter
 	for k := range self.bucketMatches {
-		if !stringz.Contains(self.ignore, k.Id()) {
+		if self.isReported(k) {
 			filters = append(filters, k)
 		}
 	}
@@ -125,9 +282,25 @@ func (self *LogSummaryHandler) dumpBucketJson() {
 
 	model := make(map[string]interface{})
 	model["timestamp"] = self.currentBucket.Format(time.RFC3339)
+	if self.bucketLate {
+		model["late"] = true
+	}
 	for _, filter := range filters {
 		model[filter.Id()] = self.bucketMatches[filter]
 	}
+	if self.groupBy != "" {
+		groupsModel := map[string]interface{}{}
+		for _, filter := range filters {
+			groups, others := self.getTopGroups(filter)
+			filterGroups := map[string]interface{}{"values": groups}
+			if others > 0 {
+				filterGroups["others"] = others
+			}
+			groupsModel[filter.Id()] = filterGroups
+		}
+		model["groupBy"] = self.groupBy
+		model["groups"] = groupsModel
+	}
 	if self.unmatched > 0 {
 		model["unmatched"] = fmt.Sprintf("    unmatched: %0000v\n", self.unmatched)
 	}
@@ -140,3 +313,47 @@ func (self *LogSummaryHandler) dumpBucketJson() {
 	fmt.Printf("%s\n", string(j))
 
 }
+
+// dumpOverlaps outputs how often each pair of filters matched the same entry, most frequent first. Overlaps are only
+// recorded when every filter is checked against each entry
+func (self *LogSummaryHandler) dumpOverlaps() {
+	if len(self.overlaps) == 0 {
+		return
+	}
+
+	var pairs []filterPair
+	for k := range self.overlaps {
+		pairs = append(pairs, k)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if self.overlaps[pairs[i]] != self.overlaps[pairs[j]] {
+			return self.overlaps[pairs[i]] > self.overlaps[pairs[j]]
+		}
+		if pairs[i].first != pairs[j].first {
+			return pairs[i].first < pairs[j].first
+		}
+		return pairs[i].second < pairs[j].second
+	})
+
+	if self.formatter == "json" {
+		var overlaps []map[string]interface{}
+		for _, pair := range pairs {
+			overlaps = append(overlaps, map[string]interface{}{
+				"filters": []string{pair.first, pair.second},
+				"count":   self.overlaps[pair],
+			})
+		}
+		j, err := json.Marshal(map[string]interface{}{"overlaps": overlaps})
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%s\n", string(j))
+		return
+	}
+
+	fmt.Printf("overlapping filters\n---------------------------------------------------\n")
+	for _, pair := range pairs {
+		fmt.Printf("    %v + %v: %v\n", pair.first, pair.second, self.overlaps[pair])
+	}
+	fmt.Println()
+}
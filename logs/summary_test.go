@@ -0,0 +1,83 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSummaryIdleWithReplayedLogs checks that idle ticks don't close the interval of replayed entries just because the
+// wall clock is past it, and that once an interval has been output, more entries in it are counted and output as late
+// entries, without the ones already output
+func TestSummaryIdleWithReplayedLogs(t *testing.T) {
+	parser := newTestParser(t, "router")
+	selector, err := newFilterSelector(nil, nil, parser.filters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := &LogSummaryHandler{
+		bucketSize:    time.Hour,
+		bucketMatches: map[LogFilter]int{},
+		selector:      selector,
+		formatter:     "json",
+	}
+	parser.handler = handler
+
+	line := `{"file":"github.com/openziti/foundation@v0.15.0/channel2/reconnecting_impl.go:86","level":"info","msg":"reconnected","time":"2024-03-01T10:15:00Z"}` + "\n"
+	if err = parser.scanLines(line); err != nil {
+		t.Fatal(err)
+	}
+
+	bucket := handler.currentBucket
+	if err = handler.HandleIdle(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if handler.bucketFlushed {
+		t.Fatal("expected the interval to stay open, since the log time hasn't passed it")
+	}
+
+	if err = handler.HandleIdle(time.Now().Add(2 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if !handler.bucketFlushed || handler.currentBucket != bucket {
+		t.Fatalf("expected the interval to be output and stay current, got flushed: %v, bucket: %v", handler.bucketFlushed, handler.currentBucket)
+	}
+
+	if err = parser.scanLines(line); err != nil {
+		t.Fatal(err)
+	}
+	if handler.currentBucket != bucket {
+		t.Fatalf("expected the bucket to stay %v, got %v", bucket, handler.currentBucket)
+	}
+	total := 0
+	for _, count := range handler.bucketMatches {
+		total += count
+	}
+	if total != 1 || !handler.bucketLate || handler.bucketFlushed {
+		t.Errorf("expected only the late match to be pending output, got %v matches, late: %v, flushed: %v", total, handler.bucketLate, handler.bucketFlushed)
+	}
+
+	// the next interval isn't late
+	next := `{"file":"github.com/openziti/foundation@v0.15.0/channel2/reconnecting_impl.go:86","level":"info","msg":"reconnected","time":"2024-03-01T11:15:00Z"}` + "\n"
+	if err = parser.scanLines(next); err != nil {
+		t.Fatal(err)
+	}
+	if handler.currentBucket == bucket || handler.bucketLate {
+		t.Errorf("expected a new interval which isn't late, got %v, late: %v", handler.currentBucket, handler.bucketLate)
+	}
+}
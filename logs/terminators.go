@@ -0,0 +1,277 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var terminatorIdRegex = regexp.MustCompile(`\bt/([\w.\-]+)`)
+var serviceIdRegex = regexp.MustCompile(`\bs/([\w.\-]+)`)
+
+func getFirstField(ctx *JsonParseContext, fields ...string) string {
+	for _, field := range fields {
+		if v := ctx.GetString(field); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func getTerminatorId(ctx *JsonParseContext) string {
+	if v := getFirstField(ctx, "terminatorId", "terminator"); v != "" {
+		return v
+	}
+	if match := terminatorIdRegex.FindStringSubmatch(ctx.GetString("msg")); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+func getServiceId(ctx *JsonParseContext) string {
+	if v := getFirstField(ctx, "serviceId", "service", "serviceName"); v != "" {
+		return v
+	}
+	if match := serviceIdRegex.FindStringSubmatch(ctx.GetString("msg")); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+type terminatorUpdate struct {
+	Time       time.Time `json:"time"`
+	Precedence string    `json:"precedence,omitempty"`
+	Cost       string    `json:"cost,omitempty"`
+}
+
+type terminatorRecord struct {
+	Id         string              `json:"id"`
+	Service    string              `json:"service,omitempty"`
+	Router     string              `json:"router,omitempty"`
+	Created    *time.Time          `json:"created,omitempty"`
+	Updates    []*terminatorUpdate `json:"updates,omitempty"`
+	Removed    *time.Time          `json:"removed,omitempty"`
+	Recreated  int                 `json:"recreated,omitempty"`
+	Precedence string              `json:"precedence,omitempty"`
+	Cost       string              `json:"cost,omitempty"`
+	firstSeen  time.Time
+}
+
+type terminatorServiceSummary struct {
+	Service         string              `json:"service"`
+	Terminators     []*terminatorRecord `json:"terminators"`
+	Active          int                 `json:"active"`
+	NoneRemaining   bool                `json:"noTerminatorsRemaining,omitempty"`
+	NoneRemainingAt *time.Time          `json:"noTerminatorsRemainingAt,omitempty"`
+	lastRemoved     time.Time
+	hasRemovals     bool
+}
+
+// TerminatorTimelineHandler tracks when terminators were created, updated and removed, grouped by service
+type TerminatorTimelineHandler struct {
+	terminators map[string]*terminatorRecord
+	formatter   string
+}
+
+func NewTerminatorTimelineHandler(formatter string) *TerminatorTimelineHandler {
+	return &TerminatorTimelineHandler{
+		terminators: map[string]*terminatorRecord{},
+		formatter:   formatter,
+	}
+}
+
+func (self *TerminatorTimelineHandler) HandleNewLine(*JsonParseContext) error {
+	return nil
+}
+
+func (self *TerminatorTimelineHandler) HandleMatch(ctx *JsonParseContext, logFilter LogFilter) error {
+	id := logFilter.Id()
+	if id != "TERMINATOR_CREATED" && id != "TERMINATOR_UPDATED" && id != "TERMINATOR_REMOVED" {
+		return nil
+	}
+
+	terminatorId := getTerminatorId(ctx)
+	if terminatorId == "" {
+		return nil
+	}
+
+	t, err := ctx.GetTime()
+	if err != nil {
+		return err
+	}
+
+	terminator, found := self.terminators[terminatorId]
+	if !found {
+		terminator = &terminatorRecord{Id: terminatorId, firstSeen: t}
+		self.terminators[terminatorId] = terminator
+	}
+
+	if terminator.Service == "" {
+		terminator.Service = getServiceId(ctx)
+	}
+	if terminator.Router == "" {
+		terminator.Router = ctx.GetString("routerId")
+	}
+
+	precedence := ctx.GetString("precedence")
+	cost := getFirstField(ctx, "cost", "staticCost")
+
+	switch id {
+	case "TERMINATOR_CREATED":
+		// a terminator may be removed and created again with the same id, in which case it's active again, and shows
+		// the latest create time along with the number of re-creates
+		if terminator.Removed != nil {
+			terminator.Removed = nil
+			terminator.Recreated++
+			terminator.Created = &t
+		} else {
+			setIfUnset(&terminator.Created, t)
+		}
+	case "TERMINATOR_UPDATED":
+		terminator.Updates = append(terminator.Updates, &terminatorUpdate{
+			Time:       t,
+			Precedence: precedence,
+			Cost:       cost,
+		})
+	case "TERMINATOR_REMOVED":
+		setIfUnset(&terminator.Removed, t)
+	}
+
+	if precedence != "" {
+		terminator.Precedence = precedence
+	}
+	if cost != "" {
+		terminator.Cost = cost
+	}
+
+	return nil
+}
+
+func (self *TerminatorTimelineHandler) HandleUnmatched(*JsonParseContext) error {
+	return nil
+}
+
+func (self *TerminatorTimelineHandler) HandleEnd(*JsonParseContext) {
+	serviceList := self.getServices()
+	if self.formatter == "json" {
+		self.dumpJson(serviceList)
+	} else {
+		self.dumpText(serviceList)
+	}
+}
+
+// getServices groups the terminators by service, and works out which services ended up without terminators
+func (self *TerminatorTimelineHandler) getServices() []*terminatorServiceSummary {
+	services := map[string]*terminatorServiceSummary{}
+	for _, terminator := range self.terminators {
+		service, found := services[terminator.Service]
+		if !found {
+			service = &terminatorServiceSummary{Service: terminator.Service}
+			services[terminator.Service] = service
+		}
+		service.Terminators = append(service.Terminators, terminator)
+		if terminator.Removed == nil {
+			service.Active++
+		} else {
+			service.hasRemovals = true
+			if terminator.Removed.After(service.lastRemoved) {
+				service.lastRemoved = *terminator.Removed
+			}
+		}
+	}
+
+	var serviceList []*terminatorServiceSummary
+	for _, service := range services {
+		sort.Slice(service.Terminators, func(i, j int) bool {
+			return service.Terminators[i].firstSeen.Before(service.Terminators[j].firstSeen)
+		})
+		// we can only say a service ended up without terminators if we know which service the terminators belonged to
+		if service.Service != "" && service.Active == 0 && service.hasRemovals {
+			service.NoneRemaining = true
+			service.NoneRemainingAt = &service.lastRemoved
+		}
+		serviceList = append(serviceList, service)
+	}
+
+	sort.Slice(serviceList, func(i, j int) bool {
+		return serviceList[i].Service < serviceList[j].Service
+	})
+	return serviceList
+}
+
+func (self *TerminatorTimelineHandler) dumpText(services []*terminatorServiceSummary) {
+	for _, service := range services {
+		name := service.Service
+		if name == "" {
+			name = "<unknown service>"
+		}
+		fmt.Printf("%v (active terminators: %v)\n---------------------------------------------------\n", name, service.Active)
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "    TERMINATOR\tROUTER\tCREATED\tRECREATED\tUPDATES\tREMOVED\tPRECEDENCE\tCOST")
+		for _, terminator := range service.Terminators {
+			_, _ = fmt.Fprintf(w, "    %v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", terminator.Id, terminator.Router,
+				formatOptionalTime(terminator.Created), terminator.Recreated, len(terminator.Updates),
+				formatOptionalTime(terminator.Removed), terminator.Precedence, terminator.Cost)
+		}
+		_ = w.Flush()
+
+		for _, terminator := range service.Terminators {
+			for _, update := range terminator.Updates {
+				fmt.Printf("    %v: %v updated precedence=%v cost=%v\n", update.Time.Format(time.RFC3339),
+					terminator.Id, update.Precedence, update.Cost)
+			}
+		}
+
+		if service.NoneRemaining {
+			fmt.Printf("    WARN: no terminators remaining after %v\n", service.NoneRemainingAt.Format(time.RFC3339))
+		}
+		fmt.Println()
+	}
+}
+
+func (self *TerminatorTimelineHandler) dumpJson(services []*terminatorServiceSummary) {
+	if services == nil {
+		services = []*terminatorServiceSummary{}
+	}
+
+	j, err := json.Marshal(map[string]interface{}{
+		"services": services,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s\n", string(j))
+}
+
+func (self *JsonLogsParser) showTerminators(_ *cobra.Command, args []string) error {
+	if err := self.validate(); err != nil {
+		return err
+	}
+
+	self.handler = NewTerminatorTimelineHandler(self.formatter)
+
+	return self.scan(args)
+}
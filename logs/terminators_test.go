@@ -0,0 +1,61 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"testing"
+)
+
+// TestTerminatorRecreated checks that a terminator created again after being removed counts as active, with its latest
+// create time, so only the service whose terminator stayed removed is reported as having none remaining
+func TestTerminatorRecreated(t *testing.T) {
+	parser := newTestParser(t, "controller")
+	handler := NewTerminatorTimelineHandler("json")
+	parser.handler = handler
+
+	lines := `{"file":"github.com/openziti/fabric@v0.22.0/controller/handler_ctrl/create_terminator.go:70","level":"info","msg":"created terminator","terminatorId":"5kM9bXq","serviceId":"3GpHcRw","routerId":"Kd8xq2","time":"2024-03-01T10:00:00Z"}
+{"file":"github.com/openziti/fabric@v0.22.0/controller/handler_ctrl/create_terminator.go:70","level":"info","msg":"created terminator","terminatorId":"7aR2mPz","serviceId":"9QwLtVn","routerId":"Kd8xq2","time":"2024-03-01T10:00:01Z"}
+{"file":"github.com/openziti/fabric@v0.22.0/controller/handler_ctrl/remove_terminators.go:62","level":"info","msg":"removed terminator [t/5kM9bXq] for service [s/3GpHcRw]","time":"2024-03-01T10:05:00Z"}
+{"file":"github.com/openziti/fabric@v0.22.0/controller/handler_ctrl/remove_terminators.go:62","level":"info","msg":"removed terminator [t/7aR2mPz] for service [s/9QwLtVn]","time":"2024-03-01T10:05:00Z"}
+{"file":"github.com/openziti/fabric@v0.22.0/controller/handler_ctrl/create_terminator.go:70","level":"info","msg":"created terminator","terminatorId":"5kM9bXq","serviceId":"3GpHcRw","routerId":"Kd8xq2","time":"2024-03-01T10:06:00Z"}
+`
+	if err := parser.scanLines(lines); err != nil {
+		t.Fatal(err)
+	}
+
+	services := handler.getServices()
+	if len(services) != 2 {
+		t.Fatalf("expected 2 services, got %v", len(services))
+	}
+
+	recreated := services[0]
+	if recreated.Service != "3GpHcRw" || recreated.Active != 1 || recreated.NoneRemaining {
+		t.Errorf("expected 3GpHcRw to have 1 active terminator, got %v active, none remaining: %v", recreated.Active, recreated.NoneRemaining)
+	}
+	terminator := recreated.Terminators[0]
+	if terminator.Removed != nil || terminator.Recreated != 1 {
+		t.Errorf("expected 5kM9bXq to be re-created once and not removed, got %v re-creates, removed at %v", terminator.Recreated, formatOptionalTime(terminator.Removed))
+	}
+	if created := formatOptionalTime(terminator.Created); created != "2024-03-01T10:06:00Z" {
+		t.Errorf("expected 5kM9bXq to show when it was re-created, 2024-03-01T10:06:00Z, got %v", created)
+	}
+
+	removed := services[1]
+	if removed.Service != "9QwLtVn" || removed.Active != 0 || !removed.NoneRemaining {
+		t.Errorf("expected 9QwLtVn to have no terminators remaining, got %v active, none remaining: %v", removed.Active, removed.NoneRemaining)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+)
+
+// pfxlogTimestampFormat is the format pfxlog uses for absolute timestamps. Relative timestamps are shown as seconds
+// since startup
+const pfxlogTimestampFormat = "2006-01-02 15:04:05.000"
+
+// pfxlogLineRegex matches lines from the pfxlog text formatter: '[<time>] <level> <func> |<channels>| [<context>]: <msg>'
+var pfxlogLineRegex = regexp.MustCompile(`^\[\s*([^\]]+)]\s+(PANIC|FATAL|ERROR|WARNING|INFO|DEBUG|TRACE) (\S*)(?: \|([^|]*)\|)?(?: \[(.*?)])?: (.*)$`)
+
+// pfxlogFieldRegex matches the start of a pfxlog key=[value] field
+var pfxlogFieldRegex = regexp.MustCompile(`^ [\w.\-]+=\[`)
+
+// pfxlogFileRegex matches a [file:line] prefix, which is logged in place of the context when caller files are enabled
+var pfxlogFileRegex = regexp.MustCompile(`^[\w.\-@/]+\.go:\d+$`)
+
+// parseTextEntry parses lines written by the pfxlog or logrus text formatters into the same fields that the json
+// formatter produces. It returns nil if the line isn't in either format
+func (self *JsonParseContext) parseTextEntry(input string) *gabs.Container {
+	var fields map[string]interface{}
+	if match := pfxlogLineRegex.FindStringSubmatch(input); match != nil {
+		fields = self.parsePfxlogEntry(match)
+	} else if strings.HasPrefix(input, "time=") || strings.HasPrefix(input, "level=") {
+		fields = parseLogfmt(input)
+	}
+
+	if fields == nil {
+		return nil
+	}
+
+	// relative timestamps and logrus timestamps in a custom format are replaced with the log collector timestamp
+	if ts, ok := fields["time"].(string); !ok || !isRFC3339(ts) {
+		delete(fields, "time")
+		if !self.outerTime.IsZero() {
+			fields["time"] = self.outerTime.Format(time.RFC3339Nano)
+		}
+	}
+
+	return gabs.Wrap(fields)
+}
+
+func (self *JsonParseContext) parsePfxlogEntry(match []string) map[string]interface{} {
+	fields := map[string]interface{}{
+		"level": strings.ToLower(match[2]),
+	}
+
+	if t, err := time.ParseInLocation(pfxlogTimestampFormat, match[1], self.options.Location); err == nil {
+		fields["time"] = t.Format(time.RFC3339Nano)
+	}
+
+	// pfxlog only logs the source file when it's in place of the context. Filters on the file are checked against the
+	// package of the function for entries without one, see getFuncPackageDir
+	if match[3] != "" {
+		fields["func"] = match[3]
+	}
+	if match[4] != "" {
+		fields["_channels"] = match[4]
+	}
+	if pfxlogFileRegex.MatchString(match[5]) {
+		file := match[5]
+		if pkg := getFuncPackage(match[3]); pkg != "" && !strings.Contains(file, "/") {
+			file = pkg + "/" + file
+		}
+		fields["file"] = file
+	} else if match[5] != "" {
+		fields["_context"] = match[5]
+	}
+
+	msg := match[6]
+	if strings.HasPrefix(msg, "{") {
+		var ok bool
+		if msg, ok = parsePfxlogFields(msg[1:], fields); !ok {
+			msg = match[6]
+		}
+	}
+	fields["msg"] = msg
+	return fields
+}
+
+// getFuncPackage returns the package path of a function, ex: github.com/openziti/fabric/router/handler_ctrl for
+// github.com/openziti/fabric/router/handler_ctrl.(*routeHandler).HandleReceive
+func getFuncPackage(fn string) string {
+	idx := strings.LastIndex(fn, "/") + 1
+	pkg, _, found := strings.Cut(fn[idx:], ".")
+	if !found || pkg == "" {
+		return ""
+	}
+	return fn[:idx] + pkg
+}
+
+// getFuncPackageDir returns the package directory of the function the entry was logged from, ending in '/', for
+// entries which don't log a source file, such as pfxlog text entries. It returns false if the entry has a source file
+// or no function
+func (self *JsonParseContext) getFuncPackageDir() (string, bool) {
+	if self.GetString("file") != "" {
+		return "", false
+	}
+	pkg := getFuncPackage(self.GetString("func"))
+	if pkg == "" {
+		return "", false
+	}
+	return pkg + "/", true
+}
+
+// getFileDir returns the directory part of a file name, such as handler_ctrl/ for handler_ctrl/route.go. Only the
+// directory of a file can be checked against the package directory of a function
+func getFileDir(file string) string {
+	return file[:strings.LastIndex(file, "/")+1]
+}
+
+// parsePfxlogFields parses the '{key=[value] key=[value]} ' block which pfxlog puts before the message, returning the
+// remaining message. Values aren't escaped, so a value ends at the first '] ' followed by another key or by the
+// closing brace
+func parsePfxlogFields(s string, fields map[string]interface{}) (string, bool) {
+	for {
+		key, rest, found := strings.Cut(s, "=[")
+		if !found || strings.ContainsAny(key, " ]") {
+			return "", false
+		}
+
+		end := -1
+		for offset := 0; end < 0; {
+			idx := strings.IndexByte(rest[offset:], ']')
+			if idx < 0 {
+				return "", false
+			}
+			idx += offset
+			next := rest[idx+1:]
+			if strings.HasPrefix(next, "} ") || next == "}" || pfxlogFieldRegex.MatchString(next) {
+				end = idx
+			}
+			offset = idx + 1
+		}
+
+		fields[key] = rest[:end]
+		s = rest[end+1:]
+		if strings.HasPrefix(s, "}") {
+			return strings.TrimPrefix(s[1:], " "), true
+		}
+		s = s[1:]
+	}
+}
+
+// parseLogfmt parses the key=value pairs written by the logrus text formatter, where values which contain special
+// characters are quoted
+func parseLogfmt(s string) map[string]interface{} {
+	fields := map[string]interface{}{}
+	for {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			return fields
+		}
+
+		key, rest, found := strings.Cut(s, "=")
+		if !found || strings.Contains(key, " ") {
+			return nil
+		}
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil
+			}
+			if value, err = strconv.Unquote(quoted); err != nil {
+				return nil
+			}
+			rest = rest[len(quoted):]
+		} else {
+			value, rest, _ = strings.Cut(rest, " ")
+		}
+
+		fields[key] = value
+		s = rest
+	}
+}
+
+func isRFC3339(s string) bool {
+	_, err := time.Parse(time.RFC3339, s)
+	return err == nil
+}
@@ -0,0 +1,140 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// moduleVersionSuffixRegex matches the module version in a source path, which isn't part of the function's package
+var moduleVersionSuffixRegex = regexp.MustCompile(`@[^/]+`)
+
+// TestPfxlogFilesInSamePackage checks that pfxlog text entries, which don't log a source file, aren't matched by
+// filters for other files in the same package
+func TestPfxlogFilesInSamePackage(t *testing.T) {
+	parser := newTestParser(t, "router")
+	handler := &sampleHandler{}
+	parser.handler = handler
+
+	lines := `[  12.345]    INFO foundation/channel2.(*reconnectingImpl).Rx: reconnected
+[  12.346]    INFO foundation/channel2.(*reconnectingDialer).Reconnect: reconnected
+[  12.347]   ERROR foundation/channel2.(*reconnectingDialer).Reconnect: reconnection attempt [1] failed
+[  12.348]   ERROR foundation/channel2.(*reconnectingImpl).Rx: reconnection attempt [1] failed
+`
+	if err := parser.scanLines(lines); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		{"CTRL_CH_RECONNECT_OK"},
+		nil,
+		{"CTRL_CH_RECONNECT_ERR"},
+		nil,
+	}
+	if len(handler.results) != len(expected) {
+		t.Fatalf("expected %v entries, got %v", len(expected), len(handler.results))
+	}
+	for idx, result := range handler.results {
+		ids := result.getIds()
+		if len(ids) != len(expected[idx]) || (len(ids) > 0 && ids[0] != expected[idx][0]) {
+			t.Errorf("line %v: expected %v, got %v", idx+1, expected[idx], ids)
+		}
+	}
+}
+
+// TestPfxlogFileScopedFilters checks the built-in samples which give a source file as pfxlog text entries, which only
+// log the function, so that filters on the file still match entries from the pfxlog text formatter
+func TestPfxlogFileScopedFilters(t *testing.T) {
+	for _, component := range []string{"router", "controller"} {
+		t.Run(component, func(t *testing.T) {
+			parser := newTestParser(t, component)
+
+			samples, err := getBuiltInSamples(component)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var variants []*filterSample
+			for _, sample := range samples {
+				if variant := getPfxlogVariant(sample); variant != nil {
+					variants = append(variants, variant)
+				}
+			}
+			if len(variants) == 0 {
+				t.Fatal("no samples with a source file")
+			}
+
+			for _, failure := range parser.testSamples(variants) {
+				t.Errorf("%v: %v", failure, failure.sample.Line)
+			}
+		})
+	}
+}
+
+// getPfxlogVariant returns the sample as a pfxlog text line, logged from a function in the package of the sample's
+// source file, or nil if the sample isn't a json entry with a source file. Samples with fields containing key=[value]
+// pairs are skipped, as pfxlog doesn't escape them and they can't be parsed back reliably
+func getPfxlogVariant(sample *filterSample) *filterSample {
+	for k, v := range sample.Entry {
+		if k != "msg" && strings.Contains(fmt.Sprintf("%v", v), "=[") {
+			return nil
+		}
+	}
+
+	file, _ := sample.Entry["file"].(string)
+	pkgDir := getFileDir(moduleVersionSuffixRegex.ReplaceAllString(file, ""))
+	if pkgDir == "" {
+		return nil
+	}
+
+	fn, _ := sample.Entry["func"].(string)
+	if fn == "" {
+		fn = pkgDir[:len(pkgDir)-1] + ".(*handler).HandleReceive"
+	}
+
+	level, _ := sample.Entry["level"].(string)
+	if level == "" {
+		level = "info"
+	} else if level == "warn" {
+		level = "warning"
+	}
+
+	var fields []string
+	for k, v := range sample.Entry {
+		if k != "file" && k != "func" && k != "level" && k != "msg" && k != "time" {
+			fields = append(fields, fmt.Sprintf("%v=[%v]", k, v))
+		}
+	}
+	sort.Strings(fields)
+
+	msg := fmt.Sprintf("%v", sample.Entry["msg"])
+	if len(fields) > 0 {
+		msg = "{" + strings.Join(fields, " ") + "} " + msg
+	}
+
+	return &filterSample{
+		Filter:  sample.Filter,
+		Version: sample.Version,
+		Line:    fmt.Sprintf("[  12.345] %7v %v: %v", strings.ToUpper(level), fn, msg),
+		Fields:  sample.Fields,
+		source:  sample.source + " as pfxlog",
+	}
+}
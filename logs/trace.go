@@ -0,0 +1,179 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openziti/foundation/v2/stringz"
+	"github.com/spf13/cobra"
+)
+
+type traceNode struct {
+	Node      string    `json:"node"`
+	Component string    `json:"component"`
+	Entries   int       `json:"entries"`
+	First     time.Time `json:"first"`
+	Last      time.Time `json:"last"`
+	Filters   []string  `json:"filters"`
+}
+
+// getIdMatcher returns a predicate which is true for entries mentioning the id. It checks the whole line, as circuit
+// ids may be logged in fields such as circuitId, in messages and errors as [s/<id>] or circuit=[<id>], or in the
+// channel context
+func getIdMatcher(id string) func(ctx *JsonParseContext) bool {
+	idRegex := regexp.MustCompile(`(^|[^\w\-])` + regexp.QuoteMeta(id) + `($|[^\w\-])`)
+	return func(ctx *JsonParseContext) bool {
+		return strings.Contains(ctx.line, id) && idRegex.MatchString(ctx.line)
+	}
+}
+
+// CircuitTracer follows a circuit through the logs of the controller and routers it was routed over
+type CircuitTracer struct {
+	multiSourceOptions
+}
+
+func NewTraceCmd() *cobra.Command {
+	traceCmd := &cobra.Command{
+		Use:   "trace",
+		Short: "follow circuits and links through the logs of several nodes",
+	}
+
+	circuitTracer := &CircuitTracer{}
+	traceCircuitCmd := &cobra.Command{
+		Use:   "circuit <circuit id> [<label>=<file>...]",
+		Short: "Show a timeline of every controller and router log entry mentioning a circuit",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  circuitTracer.run,
+	}
+	circuitTracer.addArgs(traceCircuitCmd)
+
+	traceCmd.AddCommand(traceCircuitCmd, newTraceLinksCmd())
+	return traceCmd
+}
+
+func (self *CircuitTracer) run(cmd *cobra.Command, args []string) error {
+	circuitId := strings.TrimPrefix(args[0], "s/")
+
+	sources, err := self.getSources(args[1:])
+	if err != nil {
+		return err
+	}
+
+	streams, columns, err := self.startStreams(cmd, sources, getIdMatcher(circuitId))
+	if err != nil {
+		return err
+	}
+
+	// entries are output as they're merged, so that only the per node summary is kept
+	var output func(entry *sourceEntry) error
+	var jsonOutput *jsonEntriesOutput
+	if self.formatter == "json" {
+		if jsonOutput, err = startJsonEntries(map[string]interface{}{"circuitId": circuitId}); err != nil {
+			return err
+		}
+		output = jsonOutput.write
+	} else {
+		output = columns.newTextOutput(true)
+	}
+
+	summary := newTraceSummary(sources)
+	err = mergeSourceStreams(streams, func(entry *sourceEntry) error {
+		summary.add(entry)
+		return output(entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput != nil {
+		nodes := summary.nodes
+		if nodes == nil {
+			nodes = []*traceNode{}
+		}
+		return jsonOutput.finish(map[string]interface{}{"nodes": nodes})
+	}
+	summary.dumpText(circuitId)
+	return nil
+}
+
+// traceSummary tracks the number of entries and the time span of the trace, along with the entries for each node, in
+// the order the nodes were first seen
+type traceSummary struct {
+	components map[string]string
+	nodes      []*traceNode
+	byNode     map[string]*traceNode
+	entries    int
+	first      time.Time
+	last       time.Time
+}
+
+func newTraceSummary(sources []*logSource) *traceSummary {
+	result := &traceSummary{
+		components: map[string]string{},
+		byNode:     map[string]*traceNode{},
+	}
+	for _, source := range sources {
+		result.components[source.label] = source.component
+	}
+	return result
+}
+
+// add counts the entry, and sets its offset from the first entry of the trace
+func (self *traceSummary) add(entry *sourceEntry) {
+	if self.entries == 0 {
+		self.first = entry.Time
+	}
+	self.entries++
+	self.last = entry.Time
+	entry.Offset = entry.Time.Sub(self.first).String()
+
+	node, found := self.byNode[entry.Node]
+	if !found {
+		node = &traceNode{Node: entry.Node, Component: self.components[entry.Node], First: entry.Time, Filters: []string{}}
+		self.byNode[entry.Node] = node
+		self.nodes = append(self.nodes, node)
+	}
+	node.Entries++
+	node.Last = entry.Time
+	if entry.Filter != "" && !stringz.Contains(node.Filters, entry.Filter) {
+		node.Filters = append(node.Filters, entry.Filter)
+	}
+}
+
+func (self *traceSummary) dumpText(circuitId string) {
+	if self.entries == 0 {
+		fmt.Printf("no entries found for circuit %v\n", circuitId)
+		return
+	}
+
+	fmt.Printf("\ncircuit %v: %v entries from %v nodes, %v to %v (%v)\n\n", circuitId, self.entries, len(self.nodes),
+		self.first.Format(entryTimeFormat), self.last.Format(entryTimeFormat), self.last.Sub(self.first))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NODE\tCOMPONENT\tENTRIES\tFIRST\tLAST\tFILTERS")
+	for _, node := range self.nodes {
+		_, _ = fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", node.Node, node.Component, node.Entries,
+			node.First.Format(entryTimeFormat), node.Last.Format(entryTimeFormat), strings.Join(node.Filters, ", "))
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,239 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// processStartFilterId is the id of the filter which matches the startup message containing the process version
+const processStartFilterId = "PROCESS_START"
+
+var zitiVersionRegex = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)(?:-([\w.\-]+))?(?:\+[\w.\-]+)?$`)
+
+// zitiVersion is a parsed semantic version, such as v0.24.2 or 1.1.0-rc1
+type zitiVersion struct {
+	parts      [3]int
+	preRelease string
+	value      string
+}
+
+func parseZitiVersion(s string) (*zitiVersion, error) {
+	match := zitiVersionRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return nil, errors.Errorf("invalid version '%v'. Try format: '1.1.0'", s)
+	}
+
+	result := &zitiVersion{
+		preRelease: match[4],
+		value:      strings.TrimPrefix(strings.TrimSpace(s), "v"),
+	}
+	for i := range result.parts {
+		result.parts[i], _ = strconv.Atoi(match[i+1])
+	}
+	return result, nil
+}
+
+// compare returns a negative number if self is before other, 0 if they're the same and a positive number otherwise.
+// Pre-releases come before the release they precede and are compared by their dot-separated identifiers
+func (self *zitiVersion) compare(other *zitiVersion) int {
+	for i := range self.parts {
+		if self.parts[i] != other.parts[i] {
+			return self.parts[i] - other.parts[i]
+		}
+	}
+	switch {
+	case self.preRelease == other.preRelease:
+		return 0
+	case self.preRelease == "":
+		return 1
+	case other.preRelease == "":
+		return -1
+	}
+	return comparePreRelease(self.preRelease, other.preRelease)
+}
+
+// comparePreRelease compares pre-release strings identifier by identifier, as semver does. Numeric identifiers are
+// compared numerically and come before alphanumeric ones. Alphanumeric identifiers are compared with any digit runs
+// taken as numbers, so that rc10 comes after rc9. If all shared identifiers are equal, the longer pre-release is later
+func comparePreRelease(a, b string) int {
+	aIds := strings.Split(a, ".")
+	bIds := strings.Split(b, ".")
+	for i := 0; i < len(aIds) && i < len(bIds); i++ {
+		aNum, aErr := strconv.Atoi(aIds[i])
+		bNum, bErr := strconv.Atoi(bIds[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum != bNum {
+				return aNum - bNum
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if result := compareAlphanumeric(aIds[i], bIds[i]); result != 0 {
+				return result
+			}
+		}
+	}
+	return len(aIds) - len(bIds)
+}
+
+var digitsRegex = regexp.MustCompile(`\d+|\D+`)
+
+func compareAlphanumeric(a, b string) int {
+	aParts := digitsRegex.FindAllString(a, -1)
+	bParts := digitsRegex.FindAllString(b, -1)
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+		if aErr == nil && bErr == nil {
+			if aNum != bNum {
+				return aNum - bNum
+			}
+		} else if result := strings.Compare(aParts[i], bParts[i]); result != 0 {
+			return result
+		}
+	}
+	return len(aParts) - len(bParts)
+}
+
+func (self *zitiVersion) String() string {
+	return self.value
+}
+
+// versionRange is the inclusive range of versions a filter applies to. A nil bound means the range is open on that end
+type versionRange struct {
+	min *zitiVersion
+	max *zitiVersion
+}
+
+func getVersionRange(logFilter LogFilter) (*versionRange, error) {
+	result := &versionRange{}
+	var err error
+	if v := logFilter.MinVersion(); v != "" {
+		if result.min, err = parseZitiVersion(v); err != nil {
+			return nil, errors.Wrapf(err, "invalid min version for filter %v", logFilter.Id())
+		}
+	}
+	if v := logFilter.MaxVersion(); v != "" {
+		if result.max, err = parseZitiVersion(v); err != nil {
+			return nil, errors.Wrapf(err, "invalid max version for filter %v", logFilter.Id())
+		}
+	}
+	if result.min != nil && result.max != nil && result.min.compare(result.max) > 0 {
+		return nil, errors.Errorf("filter %v min version %v is after max version %v", logFilter.Id(), result.min, result.max)
+	}
+	return result, nil
+}
+
+func (self *versionRange) contains(v *zitiVersion) bool {
+	if v == nil {
+		return true
+	}
+	if self.min != nil && v.compare(self.min) < 0 {
+		return false
+	}
+	if self.max != nil && v.compare(self.max) > 0 {
+		return false
+	}
+	return true
+}
+
+// isObsolete returns true if the range ends before the given version
+func (self *versionRange) isObsolete(v *zitiVersion) bool {
+	return v != nil && self.max != nil && v.compare(self.max) > 0
+}
+
+func (self *versionRange) String() string {
+	switch {
+	case self.min != nil && self.max != nil:
+		return fmt.Sprintf("%v to %v", self.min, self.max)
+	case self.min != nil:
+		return fmt.Sprintf("%v and later", self.min)
+	case self.max != nil:
+		return fmt.Sprintf("up to %v", self.max)
+	}
+	return ""
+}
+
+// detectZitiVersion returns the version logged in the startup banner, if it can be parsed. Other entries matched by a
+// process start filter, such as the module versions logged after the banner, don't give the ziti version
+func detectZitiVersion(ctx *JsonParseContext) *zitiVersion {
+	if !isProcessBanner(ctx) {
+		return nil
+	}
+	version := ctx.GetString("version")
+	if version == "" {
+		version = versionRegex.FindString(ctx.GetString("msg"))
+	}
+	if v, err := parseZitiVersion(version); err == nil {
+		return v
+	}
+	return nil
+}
+
+// setupVersions checks the filter version ranges and determines which filters are active for the version given on
+// the command line. If no version was given, all filters are active until a version is detected in the logs
+func (self *JsonLogsParser) setupVersions() error {
+	self.versionRanges = map[LogFilter]*versionRange{}
+	for _, logFilter := range self.filters {
+		r, err := getVersionRange(logFilter)
+		if err != nil {
+			return err
+		}
+		self.versionRanges[logFilter] = r
+	}
+
+	self.version = nil
+	if self.zitiVersion != "" {
+		v, err := parseZitiVersion(self.zitiVersion)
+		if err != nil {
+			return errors.Wrap(err, "invalid ziti version argument")
+		}
+		self.version = v
+	}
+	self.updateActiveFilters()
+	return nil
+}
+
+func (self *JsonLogsParser) updateActiveFilters() {
+	self.activeFilters = nil
+	for _, logFilter := range self.filters {
+		if self.versionRanges[logFilter].contains(self.version) {
+			self.activeFilters = append(self.activeFilters, logFilter)
+		}
+	}
+}
+
+// handleProcessStart switches the active filters to those for the version the process logged on startup, unless a
+// version was given on the command line
+func (self *JsonLogsParser) handleProcessStart(ctx *JsonParseContext) {
+	if self.zitiVersion != "" {
+		return
+	}
+	if v := detectZitiVersion(ctx); v != nil && (self.version == nil || v.compare(self.version) != 0) {
+		self.version = v
+		self.updateActiveFilters()
+	}
+}
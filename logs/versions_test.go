@@ -0,0 +1,107 @@
+/*
+	Copyright NetFoundry Inc.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package logs
+
+import (
+	"testing"
+)
+
+// TestVersionFromBanner checks that only the banner sets the detected version, so the module versions logged after
+// it don't turn filters for old versions back on
+func TestVersionFromBanner(t *testing.T) {
+	parser := newTestParser(t, "controller")
+	handler := &sampleHandler{}
+	parser.handler = handler
+
+	lines := `{"file":"github.com/openziti/ziti/ziti-controller/subcmd/run.go:60","level":"info","msg":"starting ziti-controller version v1.1.0 of revision 7b2f4c9 (built on 2024-05-01)","time":"2024-05-01T10:00:00Z"}
+{"level":"info","msg":"ziti-fabric version 0.22.0","time":"2024-05-01T10:00:01Z"}
+{"file":"github.com/openziti/ziti/controller/network/network.go:535","func":"github.com/openziti/ziti/controller/network.(*Network).rerouteLink","level":"info","msg":"link [l/9dMe3KeLv] changed","time":"2024-05-01T10:00:02Z"}
+`
+	if err := parser.scanLines(lines); err != nil {
+		t.Fatal(err)
+	}
+
+	if parser.version == nil || parser.version.String() != "1.1.0" {
+		t.Fatalf("expected version 1.1.0, got %v", parser.version)
+	}
+	if len(handler.results) != 3 {
+		t.Fatalf("expected 3 entries, got %v", len(handler.results))
+	}
+	if ids := handler.results[2].getIds(); len(ids) != 0 {
+		t.Errorf("expected the link change not to match filters obsolete for 1.1.0, got %v", ids)
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.1.0", "1.1.0", 0},
+		{"0.24.2", "1.1.0", -1},
+		{"1.1.0-rc1", "1.1.0", -1},
+		{"1.1.0-rc9", "1.1.0-rc10", -1},
+		{"1.1.0-rc.9", "1.1.0-rc.10", -1},
+		{"1.1.0-alpha", "1.1.0-beta", -1},
+		{"1.1.0-1", "1.1.0-alpha", -1},
+		{"1.1.0-alpha", "1.1.0-alpha.1", -1},
+		{"1.1.0-alpha.1", "1.1.0-alpha.beta", -1},
+		{"0.24.2-rc10", "0.24.2", -1},
+	}
+
+	for _, test := range tests {
+		a, err := parseZitiVersion(test.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := parseZitiVersion(test.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result := sign(a.compare(b)); result != test.expected {
+			t.Errorf("compare(%v, %v): expected %v, got %v", test.a, test.b, test.expected, result)
+		}
+		if result := sign(b.compare(a)); result != -test.expected {
+			t.Errorf("compare(%v, %v): expected %v, got %v", test.b, test.a, -test.expected, result)
+		}
+	}
+}
+
+func TestVersionRangeWithPreRelease(t *testing.T) {
+	r := &versionRange{}
+	var err error
+	if r.max, err = parseZitiVersion("0.24.2-rc9"); err != nil {
+		t.Fatal(err)
+	}
+	v, err := parseZitiVersion("0.24.2-rc10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.contains(v) {
+		t.Errorf("expected %v to be outside %v", v, r)
+	}
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
@@ -44,7 +44,7 @@ func init() {
 		},
 	})
 
-	root.AddCommand(logs.NewRouterLogsCmd(), logs.NewCtrlLogsCommand(), logs.NewEndpointLogsCommand())
+	root.AddCommand(logs.NewRouterLogsCmd(), logs.NewCtrlLogsCommand(), logs.NewEndpointLogsCommand(), logs.NewLogsCmd(), logs.NewTraceCmd())
 }
 
 var root = &cobra.Command{